@@ -0,0 +1,184 @@
+// Package check provides content linting for sites. Checks inspect a loaded
+// site and report problems without building any output.
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/haleyrc/stele/internal/site"
+)
+
+// Severity describes how serious an issue is.
+type Severity string
+
+const (
+	// SeverityError indicates a problem that should block publishing.
+	SeverityError Severity = "error"
+
+	// SeverityWarning indicates a problem that should be reviewed but does
+	// not block publishing.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue represents a single problem found in the site content.
+type Issue struct {
+	// The severity of the issue.
+	Severity Severity `json:"severity"`
+
+	// The name of the rule that produced the issue.
+	Rule string `json:"rule"`
+
+	// The path of the source file the issue relates to, if any.
+	Path string `json:"path,omitempty"`
+
+	// A human-readable description of the issue.
+	Message string `json:"message"`
+}
+
+// Report is the result of running checks against a site.
+type Report struct {
+	// All issues found, ordered by path and then rule.
+	Issues []Issue `json:"issues"`
+}
+
+// rule inspects a site and adds any issues it finds to the report.
+type rule func(s *site.Site, r *Report)
+
+// rules is the set of rules run by Run.
+var rules = []rule{
+	checkDuplicateSlugs,
+	checkSeriesSlugs,
+	checkNoteSlugs,
+	checkTagVariants,
+	checkTitleLength,
+	checkDescriptionLength,
+	checkImageAltText,
+	checkEmptySeries,
+	checkDraftOnlySeries,
+	checkRawHTML,
+	checkDiagrams,
+	checkWikiLinks,
+}
+
+// Run runs all of the checks against the site and returns the resulting
+// report.
+func Run(s *site.Site) *Report {
+	r := &Report{Issues: []Issue{}}
+	for _, rule := range rules {
+		rule(s, r)
+	}
+	r.sort()
+
+	return r
+}
+
+// Merge adds the issues from other to the report.
+func (r *Report) Merge(other *Report) {
+	r.Issues = append(r.Issues, other.Issues...)
+	r.sort()
+}
+
+func (r *Report) sort() {
+	sort.SliceStable(r.Issues, func(i, j int) bool {
+		if r.Issues[i].Path != r.Issues[j].Path {
+			return r.Issues[i].Path < r.Issues[j].Path
+		}
+		return r.Issues[i].Rule < r.Issues[j].Rule
+	})
+}
+
+// Errorf adds an error-level issue to the report.
+func (r *Report) Errorf(rule, path, format string, args ...any) {
+	r.add(SeverityError, rule, path, format, args...)
+}
+
+// Warnf adds a warning-level issue to the report.
+func (r *Report) Warnf(rule, path, format string, args ...any) {
+	r.add(SeverityWarning, rule, path, format, args...)
+}
+
+func (r *Report) add(severity Severity, rule, path, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{
+		Severity: severity,
+		Rule:     rule,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Errors returns the number of error-level issues in the report.
+func (r *Report) Errors() int {
+	return r.count(SeverityError)
+}
+
+// Warnings returns the number of warning-level issues in the report.
+func (r *Report) Warnings() int {
+	return r.count(SeverityWarning)
+}
+
+// HasErrors returns true if the report contains any error-level issues.
+func (r *Report) HasErrors() bool {
+	return r.Errors() > 0
+}
+
+func (r *Report) count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText writes the report in a human-readable format to w.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, issue := range r.Issues {
+		location := issue.Path
+		if location == "" {
+			location = "site"
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s (%s)\n", location, issue.Severity, issue.Message, issue.Rule); err != nil {
+			return fmt.Errorf("check: write text: %w", err)
+		}
+	}
+
+	if len(r.Issues) > 0 {
+		if _, err := fmt.Fprintln(w); err != nil {
+			return fmt.Errorf("check: write text: %w", err)
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "%d error(s), %d warning(s)\n", r.Errors(), r.Warnings()); err != nil {
+		return fmt.Errorf("check: write text: %w", err)
+	}
+
+	return nil
+}
+
+// WriteJSON writes the report as JSON to w.
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := struct {
+		Errors   int     `json:"errors"`
+		Warnings int     `json:"warnings"`
+		Issues   []Issue `json:"issues"`
+	}{
+		Errors:   r.Errors(),
+		Warnings: r.Warnings(),
+		Issues:   r.Issues,
+	}
+
+	bytes, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("check: write json: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(w, string(bytes)); err != nil {
+		return fmt.Errorf("check: write json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package check_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/check"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+const goodDescription = "A description that is comfortably within the recommended length range."
+
+func newPost(slug, path string) *site.Post {
+	return &site.Post{
+		Slug: slug,
+		Path: path,
+		Frontmatter: site.PostFrontmatter{
+			Title:       "A Post",
+			Description: goodDescription,
+			Timestamp:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Content: "<p>Content</p>",
+	}
+}
+
+func issuesForRule(r *check.Report, rule string) []check.Issue {
+	var issues []check.Issue
+	for _, issue := range r.Issues {
+		if issue.Rule == rule {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+func TestRun_CleanSite(t *testing.T) {
+	s := &site.Site{
+		Posts: site.Posts{newPost("hello", "posts/hello.md")},
+	}
+
+	r := check.Run(s)
+
+	assert.Equal(t, "issue count", 0, len(r.Issues))
+	assert.False(t, "has errors", r.HasErrors())
+}
+
+func TestRun_DuplicateSlugs(t *testing.T) {
+	series := &site.Series{Slug: "go-basics"}
+	seriesPost := newPost("go-basics/variables", "posts/go-basics/variables.md")
+	seriesPost.Series = series
+	series.Posts = site.Posts{seriesPost}
+
+	s := &site.Site{
+		Posts: site.Posts{
+			newPost("Hello", "posts/Hello.md"),
+			newPost("hello", "posts/hello.md"),
+			newPost("variables", "posts/variables.md"),
+			seriesPost,
+		},
+		Series: site.AllSeries{series},
+	}
+
+	r := check.Run(s)
+	issues := issuesForRule(r, "duplicate-slug")
+
+	assert.Equal(t, "issue count", 3, len(issues))
+	assert.Equal(t, "error count", 2, r.Errors())
+	assert.Equal(t, "warning path", "posts/variables.md", issues[2].Path)
+	assert.Equal(t, "warning severity", check.SeverityWarning, issues[2].Severity)
+}
+
+func TestRun_SeriesSlugConflict(t *testing.T) {
+	series := &site.Series{Slug: "archive"}
+	post := newPost("archive/one", "posts/archive/one.md")
+	post.Series = series
+	series.Posts = site.Posts{post}
+
+	s := &site.Site{Dir: ".", Posts: site.Posts{post}, Series: site.AllSeries{series}}
+
+	issues := issuesForRule(check.Run(s), "series-slug")
+
+	assert.Equal(t, "issue count", 1, len(issues))
+	assert.Equal(t, "path", "posts/archive/index.yaml", issues[0].Path)
+}
+
+func TestRun_NoteSlugConflicts(t *testing.T) {
+	newNote := func(slug string, history bool) *site.Note {
+		return &site.Note{
+			Slug:        slug,
+			Path:        "notes/" + slug + ".md",
+			Frontmatter: site.NoteFrontmatter{Title: "Note", Tags: []string{}, History: history},
+		}
+	}
+
+	s := &site.Site{Notes: site.Notes{
+		newNote("tags/go", false),
+		newNote("graph", false),
+		newNote("graph/nodes", false),
+		newNote("vim", true),
+		newNote("vim/history", false),
+		newNote("go", false),
+		newNote("go/history", false),
+	}}
+
+	issues := issuesForRule(check.Run(s), "note-slug")
+
+	assert.Equal(t, "issue count", 3, len(issues))
+	assert.Equal(t, "graph path", "notes/graph.md", issues[0].Path)
+	assert.Equal(t, "tags path", "notes/tags/go.md", issues[1].Path)
+	assert.Equal(t, "history path", "notes/vim/history.md", issues[2].Path)
+}
+
+func TestRun_TagVariants(t *testing.T) {
+	a := newPost("a", "posts/a.md")
+	a.Frontmatter.Tags = []string{"Go", "web dev", "go-kit"}
+	b := newPost("b", "posts/b.md")
+	b.Frontmatter.Tags = []string{"go", "webdev", "testing", "gokit"}
+	c := newPost("c", "posts/c.md")
+	c.Frontmatter.Tags = []string{"Go"}
+
+	s := &site.Site{Posts: site.Posts{a, b, c}}
+
+	r := check.Run(s)
+	issues := issuesForRule(r, "tag-variant")
+
+	assert.Equal(t, "issue count", 5, len(issues))
+	assert.True(t, "has errors", r.HasErrors())
+
+	paths := map[string]int{}
+	for _, issue := range issues {
+		paths[issue.Path]++
+	}
+	assert.Equal(t, "issues for posts/a.md", 2, paths["posts/a.md"])
+	assert.Equal(t, "issues for posts/b.md", 2, paths["posts/b.md"])
+	assert.Equal(t, "issues for posts/c.md", 1, paths["posts/c.md"])
+}
+
+func TestRun_Lengths(t *testing.T) {
+	post := newPost("long", "posts/long.md")
+	post.Frontmatter.Title = strings.Repeat("x", check.MaxTitleLength+1)
+	post.Frontmatter.Description = "Too short."
+
+	r := check.Run(&site.Site{Posts: site.Posts{post}})
+
+	assert.Equal(t, "title issues", 1, len(issuesForRule(r, "title-length")))
+	assert.Equal(t, "description issues", 1, len(issuesForRule(r, "description-length")))
+	assert.False(t, "has errors", r.HasErrors())
+}
+
+func TestRun_ImageAltText(t *testing.T) {
+	post := newPost("images", "posts/images.md")
+	post.Content = `<p><img src="/cat.png"><img src="/dog.png" alt="A dog"><img src="/rule.png" alt=""></p>`
+
+	issues := issuesForRule(check.Run(&site.Site{Posts: site.Posts{post}}), "image-alt")
+
+	assert.Equal(t, "issue count", 1, len(issues))
+	assert.True(t, "mentions source", strings.Contains(issues[0].Message, "/cat.png"))
+}
+
+func TestRun_Series(t *testing.T) {
+	empty := &site.Series{Slug: "empty"}
+
+	drafts := &site.Series{Slug: "drafts"}
+	draft := newPost("drafts/one", "posts/drafts/one.md")
+	draft.Frontmatter.Draft = true
+	draft.Series = drafts
+	drafts.Posts = site.Posts{draft}
+
+	s := &site.Site{Dir: ".", Posts: site.Posts{draft}, Series: site.AllSeries{empty, drafts}}
+	r := check.Run(s)
+
+	assert.Equal(t, "empty series issues", 1, len(issuesForRule(r, "empty-series")))
+	assert.Equal(t, "draft-only series issues", 1, len(issuesForRule(r, "draft-only-series")))
+}
+
+func TestRun_RawHTML(t *testing.T) {
+	note := &site.Note{
+		Slug:        "raw",
+		Path:        "notes/raw.md",
+		Frontmatter: site.NoteFrontmatter{Title: "Raw", Tags: []string{}},
+		Content:     "<!-- raw HTML omitted -->\n<p>Text</p>",
+	}
+
+	issues := issuesForRule(check.Run(&site.Site{Notes: site.Notes{note}}), "raw-html")
+
+	assert.Equal(t, "issue count", 1, len(issues))
+	assert.Equal(t, "path", "notes/raw.md", issues[0].Path)
+}
+
+func TestRun_Diagrams(t *testing.T) {
+	post := newPost("hello", "posts/hello.md")
+	post.Content = `<div class="diagram-error" role="alert"><p><strong>Diagram error:</strong> line 3: diagram: render: tabs aren&#39;t allowed</p>` +
+		"<pre><code>a\tb</code></pre></div>"
+
+	issues := issuesForRule(check.Run(&site.Site{Posts: site.Posts{post}}), "diagram")
+
+	assert.Equal(t, "issue count", 1, len(issues))
+	assert.Equal(t, "severity", check.SeverityError, issues[0].Severity)
+	assert.Equal(t, "message", "line 3: diagram: render: tabs aren't allowed", issues[0].Message)
+}
+
+func TestRun_WikiLinks(t *testing.T) {
+	note := &site.Note{
+		Slug:            "vim",
+		Path:            "notes/vim.md",
+		Frontmatter:     site.NoteFrontmatter{Title: "Vim", Tags: []string{}},
+		UnresolvedLinks: []string{"emacs"},
+	}
+	post := newPost("hello", "posts/hello.md")
+	post.UnresolvedLinks = []string{"missing"}
+
+	r := check.Run(&site.Site{Notes: site.Notes{note}, Posts: site.Posts{post}})
+	issues := issuesForRule(r, "wiki-link")
+
+	assert.Equal(t, "issue count", 2, len(issues))
+	assert.True(t, "has errors", r.HasErrors())
+	assert.True(t, "mentions target", strings.Contains(issues[0].Message, "[[emacs]]"))
+}
+
+func TestReport_WriteText(t *testing.T) {
+	r := &check.Report{}
+	r.Errorf("duplicate-slug", "posts/a.md", "slug %q collides", "a")
+	r.Warnf("raw-html", "", "something")
+
+	var buf bytes.Buffer
+	err := r.WriteText(&buf)
+	assert.OK(t, err).Fatal()
+
+	want := `posts/a.md: error: slug "a" collides (duplicate-slug)
+site: warning: something (raw-html)
+
+1 error(s), 1 warning(s)
+`
+	assert.Equal(t, "output", want, buf.String())
+}
+
+func TestReport_WriteJSON(t *testing.T) {
+	r := &check.Report{}
+	r.Errorf("duplicate-slug", "posts/a.md", "slug collides")
+
+	var buf bytes.Buffer
+	err := r.WriteJSON(&buf)
+	assert.OK(t, err).Fatal()
+
+	var out struct {
+		Errors int           `json:"errors"`
+		Issues []check.Issue `json:"issues"`
+	}
+	err = json.Unmarshal(buf.Bytes(), &out)
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "errors", 1, out.Errors)
+	assert.Equal(t, "rule", "duplicate-slug", out.Issues[0].Rule)
+}
@@ -0,0 +1,358 @@
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/haleyrc/stele/internal/htmlutil"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+// Defaults used by NewLinkChecker.
+const (
+	DefaultConcurrency  = 8
+	DefaultHostInterval = 500 * time.Millisecond
+	DefaultTimeout      = 10 * time.Second
+)
+
+// LinkResult is the outcome of checking a single external URL.
+type LinkResult struct {
+	// The HTTP status code returned by the server, if a response was received.
+	Status int `json:"status,omitempty"`
+
+	// A description of the transport error, if no response was received.
+	Error string `json:"error,omitempty"`
+
+	// When the URL was checked.
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// OK returns true if the URL responded successfully.
+func (lr LinkResult) OK() bool {
+	return lr.Error == "" && lr.Status < 400
+}
+
+// Severity returns how serious a failed result is. Responses that commonly
+// indicate bot protection or rate limiting rather than a missing page are
+// reported as warnings.
+func (lr LinkResult) Severity() Severity {
+	switch lr.Status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return SeverityWarning
+	default:
+		return SeverityError
+	}
+}
+
+// String returns a short human-readable description of the result.
+func (lr LinkResult) String() string {
+	if lr.Error != "" {
+		return lr.Error
+	}
+	return fmt.Sprintf("%d %s", lr.Status, http.StatusText(lr.Status))
+}
+
+// LinkCache stores the results of previous external link checks on disk so
+// that repeated runs don't hammer the same servers. Only successful results
+// are cached; failures are always re-checked.
+type LinkCache struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	results map[string]LinkResult
+}
+
+// LoadLinkCache loads the cache stored at path. Entries older than ttl are
+// ignored. If the file does not exist, an empty cache is returned.
+func LoadLinkCache(path string, ttl time.Duration) (*LinkCache, error) {
+	cache := &LinkCache{
+		path:    path,
+		ttl:     ttl,
+		results: map[string]LinkResult{},
+	}
+
+	bytes, err := os.ReadFile(path) // #nosec G304 - Cache file lives in the user's site directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, fmt.Errorf("load link cache: %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(bytes, &cache.results); err != nil {
+		return nil, fmt.Errorf("load link cache: %s: %w", path, err)
+	}
+
+	return cache, nil
+}
+
+// Get returns the cached result for rawURL if one exists and has not expired.
+func (c *LinkCache) Get(rawURL string) (LinkResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.results[rawURL]
+	if !ok || time.Since(result.CheckedAt) > c.ttl {
+		return LinkResult{}, false
+	}
+	return result, true
+}
+
+// Put records the result for rawURL. Failed results are not cached.
+func (c *LinkCache) Put(rawURL string, result LinkResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if result.OK() {
+		c.results[rawURL] = result
+	} else {
+		delete(c.results, rawURL)
+	}
+}
+
+// Save writes the unexpired cache entries back to disk, creating the parent
+// directory if necessary.
+func (c *LinkCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for rawURL, result := range c.results {
+		if time.Since(result.CheckedAt) > c.ttl {
+			delete(c.results, rawURL)
+		}
+	}
+
+	bytes, err := json.MarshalIndent(c.results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save link cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0750); err != nil {
+		return fmt.Errorf("save link cache: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, bytes, 0600); err != nil {
+		return fmt.Errorf("save link cache: %w", err)
+	}
+
+	return nil
+}
+
+// LinkChecker checks external URLs concurrently while limiting how often any
+// single host is contacted.
+type LinkChecker struct {
+	// The client used to make requests.
+	Client *http.Client
+
+	// The maximum number of requests in flight at once.
+	Concurrency int
+
+	// The minimum time between requests to the same host.
+	HostInterval time.Duration
+
+	// How long to wait for each request before giving up.
+	Timeout time.Duration
+
+	// An optional cache of previous results.
+	Cache *LinkCache
+}
+
+// NewLinkChecker creates a new link checker with default limits.
+func NewLinkChecker(cache *LinkCache) *LinkChecker {
+	return &LinkChecker{
+		Client:       http.DefaultClient,
+		Concurrency:  DefaultConcurrency,
+		HostInterval: DefaultHostInterval,
+		Timeout:      DefaultTimeout,
+		Cache:        cache,
+	}
+}
+
+// Check checks each of the given URLs and returns the results keyed by URL.
+//
+// The URLs for each host are checked in turn by a queue of their own, which
+// waits out the interval between requests before taking one of the slots
+// limiting how many requests are in flight. A host with many links therefore
+// only ever holds one slot, and never while it's waiting, so it can't starve
+// the other hosts.
+func (lc *LinkChecker) Check(ctx context.Context, urls []string) map[string]LinkResult {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]LinkResult, len(urls))
+		sem     = make(chan struct{}, max(lc.Concurrency, 1))
+		queues  = map[string][]*url.URL{}
+	)
+
+	for _, rawURL := range urls {
+		if lc.Cache != nil {
+			if result, ok := lc.Cache.Get(rawURL); ok {
+				results[rawURL] = result
+				continue
+			}
+		}
+
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			results[rawURL] = LinkResult{Error: err.Error(), CheckedAt: time.Now()}
+			continue
+		}
+		queues[u.Host] = append(queues[u.Host], u)
+	}
+
+	for _, queue := range queues {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			h := &hostQueue{checker: lc, sem: sem}
+			for _, u := range queue {
+				result := h.check(ctx, u)
+				if lc.Cache != nil {
+					lc.Cache.Put(u.String(), result)
+				}
+
+				mu.Lock()
+				results[u.String()] = result
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// hostQueue makes the requests to a single host, one at a time, spaced out by
+// the host interval of the checker.
+type hostQueue struct {
+	checker *LinkChecker
+
+	// The slots limiting the number of requests in flight across all hosts.
+	sem chan struct{}
+
+	// When the last request to the host was sent.
+	last time.Time
+}
+
+func (h *hostQueue) check(ctx context.Context, u *url.URL) LinkResult {
+	// Some servers don't support HEAD requests, so fall back to GET.
+	status, err := h.request(ctx, http.MethodHead, u)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = h.request(ctx, http.MethodGet, u)
+	}
+
+	result := LinkResult{Status: status, CheckedAt: time.Now()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (h *hostQueue) request(ctx context.Context, method string, u *url.URL) (int, error) {
+	if !h.last.IsZero() {
+		timer := time.NewTimer(time.Until(h.last.Add(h.checker.HostInterval)))
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case h.sem <- struct{}{}:
+	}
+	defer func() { <-h.sem }()
+	h.last = time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, h.checker.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "stele-link-checker")
+
+	resp, err := h.checker.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close() // #nosec G104 - Body is never read
+
+	return resp.StatusCode, nil
+}
+
+// ExternalLinks returns every outbound http(s) URL referenced by posts and
+// notes, mapped to the paths of the files that reference it. Fragments are
+// removed since they don't affect whether a page exists.
+func ExternalLinks(s *site.Site) map[string][]string {
+	links := map[string][]string{}
+
+	add := func(path, content string) {
+		seen := map[string]bool{}
+		for _, link := range htmlutil.Links(content) {
+			u, err := url.Parse(link.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				continue
+			}
+			u.Fragment = ""
+
+			rawURL := u.String()
+			if !seen[rawURL] {
+				seen[rawURL] = true
+				links[rawURL] = append(links[rawURL], path)
+			}
+		}
+	}
+
+	for _, post := range s.Posts {
+		add(post.Path, post.Content)
+	}
+	for _, note := range s.Notes {
+		add(note.Path, note.Content)
+	}
+
+	return links
+}
+
+// RunExternal checks every external link in the site using lc and returns a
+// report with an issue for each dead link in each file that references it.
+func RunExternal(ctx context.Context, s *site.Site, lc *LinkChecker) *Report {
+	links := ExternalLinks(s)
+
+	urls := make([]string, 0, len(links))
+	for rawURL := range links {
+		urls = append(urls, rawURL)
+	}
+	sort.Strings(urls)
+
+	results := lc.Check(ctx, urls)
+
+	r := &Report{Issues: []Issue{}}
+	for _, rawURL := range urls {
+		result := results[rawURL]
+		if result.OK() {
+			continue
+		}
+		for _, path := range links[rawURL] {
+			r.add(result.Severity(), "external-link", path, "%s: %s", rawURL, result)
+		}
+	}
+	r.sort()
+
+	return r
+}
@@ -0,0 +1,177 @@
+package check_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/check"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+func newLinkServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/forbidden", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestLinkChecker(cache *check.LinkCache) *check.LinkChecker {
+	lc := check.NewLinkChecker(cache)
+	lc.HostInterval = 0
+	lc.Timeout = 100 * time.Millisecond
+	return lc
+}
+
+func TestLinkChecker_Check(t *testing.T) {
+	var hits atomic.Int32
+	srv := newLinkServer(t, &hits)
+
+	lc := newTestLinkChecker(nil)
+	results := lc.Check(context.Background(), []string{
+		srv.URL + "/ok",
+		srv.URL + "/gone",
+		srv.URL + "/no-head",
+		srv.URL + "/forbidden",
+		srv.URL + "/slow",
+	})
+
+	assert.True(t, "ok is ok", results[srv.URL+"/ok"].OK())
+	assert.Equal(t, "gone status", http.StatusNotFound, results[srv.URL+"/gone"].Status)
+	assert.True(t, "no-head falls back to GET", results[srv.URL+"/no-head"].OK())
+	assert.Equal(t, "forbidden severity", check.SeverityWarning, results[srv.URL+"/forbidden"].Severity())
+	assert.NotBlank(t, "slow error", results[srv.URL+"/slow"].Error)
+}
+
+func TestLinkChecker_HostInterval(t *testing.T) {
+	var hits atomic.Int32
+	srv := newLinkServer(t, &hits)
+
+	lc := newTestLinkChecker(nil)
+	lc.HostInterval = 50 * time.Millisecond
+
+	start := time.Now()
+	lc.Check(context.Background(), []string{
+		srv.URL + "/ok?a",
+		srv.URL + "/ok?b",
+		srv.URL + "/ok?c",
+	})
+	elapsed := time.Since(start)
+
+	if elapsed < 100*time.Millisecond {
+		t.Errorf("expected requests to the same host to be spaced out, but took %v", elapsed)
+	}
+}
+
+func TestLinkChecker_HostInterval_OtherHosts(t *testing.T) {
+	var hits atomic.Int32
+	busy := newLinkServer(t, &hits)
+
+	var hitAt atomic.Int64
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitAt.CompareAndSwap(0, time.Now().UnixNano())
+	}))
+	t.Cleanup(other.Close)
+
+	lc := newTestLinkChecker(nil)
+	lc.Concurrency = 1
+	lc.HostInterval = 100 * time.Millisecond
+
+	urls := []string{
+		busy.URL + "/ok?a",
+		busy.URL + "/ok?b",
+		busy.URL + "/ok?c",
+		other.URL + "/",
+		busy.URL + "/ok?d",
+		busy.URL + "/ok?e",
+	}
+
+	start := time.Now()
+	results := lc.Check(context.Background(), urls)
+
+	// The other host mustn't wait behind the queue for the busy one.
+	waited := time.Duration(hitAt.Load() - start.UnixNano())
+	assert.True(t, "other host is ok", results[other.URL+"/"].OK())
+	assert.True(t, "other host is checked without waiting", waited < 100*time.Millisecond)
+	assert.Equal(t, "hits on busy host", int32(5), hits.Load())
+}
+
+func TestLinkChecker_Cache(t *testing.T) {
+	var hits atomic.Int32
+	srv := newLinkServer(t, &hits)
+	path := filepath.Join(t.TempDir(), ".stele", "links.json")
+	urls := []string{srv.URL + "/ok", srv.URL + "/gone"}
+
+	cache, err := check.LoadLinkCache(path, time.Hour)
+	assert.OK(t, err).Fatal()
+	newTestLinkChecker(cache).Check(context.Background(), urls)
+	assert.OK(t, cache.Save()).Fatal()
+	assert.Equal(t, "hits after first run", int32(2), hits.Load())
+
+	cache, err = check.LoadLinkCache(path, time.Hour)
+	assert.OK(t, err).Fatal()
+	results := newTestLinkChecker(cache).Check(context.Background(), urls)
+
+	// Only the failed link is checked again.
+	assert.Equal(t, "hits after second run", int32(3), hits.Load())
+	assert.True(t, "cached result is ok", results[srv.URL+"/ok"].OK())
+
+	expired, err := check.LoadLinkCache(path, 0)
+	assert.OK(t, err).Fatal()
+	_, ok := expired.Get(srv.URL + "/ok")
+	assert.False(t, "expired entry is ignored", ok)
+}
+
+func TestRunExternal(t *testing.T) {
+	var hits atomic.Int32
+	srv := newLinkServer(t, &hits)
+
+	a := newPost("a", "posts/a.md")
+	a.Content = `<a href="` + srv.URL + `/ok">ok</a> <a href="` + srv.URL + `/gone#section">gone</a> <a href="/posts/b">internal</a>`
+	b := newPost("b", "posts/b.md")
+	b.Content = `<a href="` + srv.URL + `/gone">gone again</a>`
+	note := &site.Note{Path: "notes/n.md", Content: `<a href="` + srv.URL + `/forbidden">forbidden</a>`}
+
+	s := &site.Site{Posts: site.Posts{a, b}, Notes: site.Notes{note}}
+
+	r := check.RunExternal(context.Background(), s, newTestLinkChecker(nil))
+
+	assert.Equal(t, "issue count", 3, len(r.Issues))
+	assert.Equal(t, "error count", 2, r.Errors())
+	assert.Equal(t, "first path", "notes/n.md", r.Issues[0].Path)
+	assert.Equal(t, "second path", "posts/a.md", r.Issues[1].Path)
+	assert.Equal(t, "third path", "posts/b.md", r.Issues[2].Path)
+	assert.Equal(t, "gone requested once", int32(3), hits.Load())
+}
@@ -0,0 +1,305 @@
+package check
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/haleyrc/stele/internal/diagram"
+	"github.com/haleyrc/stele/internal/htmlutil"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+// Recommended lengths for titles and descriptions. These roughly match what
+// search engines display in result listings.
+const (
+	MaxTitleLength       = 70
+	MinDescriptionLength = 50
+	MaxDescriptionLength = 160
+)
+
+// rawHTMLMarker is the placeholder goldmark renders in place of raw HTML when
+// unsafe rendering is disabled.
+const rawHTMLMarker = "<!-- raw HTML omitted -->"
+
+// reservedPages are the names of top-level pages generated by the compiler.
+// A series with one of these slugs would overwrite the page.
+var reservedPages = []string{"about", "archive", "index", "notes", "search", "tags"}
+
+// document is a piece of rendered content along with its source path.
+type document struct {
+	path    string
+	content string
+}
+
+func documents(s *site.Site) []document {
+	var docs []document
+	if s.About != nil {
+		docs = append(docs, document{path: filepath.Join(s.Dir, "about.md"), content: s.About.Content})
+	}
+	for _, note := range s.Notes {
+		docs = append(docs, document{path: note.Path, content: note.Content})
+	}
+	for _, post := range s.Posts {
+		docs = append(docs, document{path: post.Path, content: post.Content})
+	}
+	return docs
+}
+
+// checkDuplicateSlugs reports posts whose URLs would collide, either exactly
+// or on case-insensitive filesystems, and standalone posts that share a name
+// with a post in a series.
+func checkDuplicateSlugs(s *site.Site, r *Report) {
+	bySlug := map[string][]*site.Post{}
+	byName := map[string][]*site.Post{}
+	for _, post := range s.Posts {
+		key := strings.ToLower(post.Slug)
+		bySlug[key] = append(bySlug[key], post)
+
+		name := strings.ToLower(filepath.Base(post.Slug))
+		byName[name] = append(byName[name], post)
+	}
+
+	for _, posts := range bySlug {
+		if len(posts) < 2 {
+			continue
+		}
+		for _, post := range posts {
+			r.Errorf("duplicate-slug", post.Path, "slug %q collides with %s", post.Slug, otherPaths(post, posts))
+		}
+	}
+
+	for _, posts := range byName {
+		if len(posts) < 2 {
+			continue
+		}
+
+		var standalone, series []*site.Post
+		for _, post := range posts {
+			if post.Series == nil {
+				standalone = append(standalone, post)
+			} else {
+				series = append(series, post)
+			}
+		}
+		if len(standalone) == 0 || len(series) == 0 {
+			continue
+		}
+
+		for _, post := range standalone {
+			r.Warnf("duplicate-slug", post.Path, "slug %q is also used by %s", post.Slug, otherPaths(post, series))
+		}
+	}
+}
+
+func otherPaths(post *site.Post, posts []*site.Post) string {
+	var paths []string
+	for _, other := range posts {
+		if other != post {
+			paths = append(paths, other.Path)
+		}
+	}
+	sort.Strings(paths)
+	return strings.Join(paths, ", ")
+}
+
+// checkSeriesSlugs reports series whose index pages would overwrite one of the
+// built-in top-level pages.
+func checkSeriesSlugs(s *site.Site, r *Report) {
+	for _, series := range s.Series {
+		for _, reserved := range reservedPages {
+			if strings.EqualFold(series.Slug, reserved) {
+				r.Errorf("series-slug", seriesPath(s, series), "series slug %q conflicts with the built-in %s page", series.Slug, reserved)
+			}
+		}
+	}
+}
+
+// checkNoteSlugs reports notes whose pages would overwrite the note tag pages,
+// the note graph page, or the history page of another note.
+func checkNoteSlugs(s *site.Site, r *Report) {
+	for _, note := range s.Notes {
+		if note.Slug == "tags" || strings.HasPrefix(note.Slug, "tags/") {
+			r.Errorf("note-slug", note.Path, "note slug %q conflicts with the built-in note tag pages", note.Slug)
+		}
+
+		if note.Slug == "graph" {
+			r.Errorf("note-slug", note.Path, "note slug %q conflicts with the built-in note graph page", note.Slug)
+		}
+
+		if parent, ok := strings.CutSuffix(note.Slug, "/history"); ok {
+			if other := s.Notes.GetBySlug(parent); other != nil && other.Frontmatter.History {
+				r.Errorf("note-slug", note.Path, "note slug %q conflicts with the history page for %s", note.Slug, other.Path)
+			}
+		}
+	}
+}
+
+// checkTagVariants reports tags that differ only by case or spacing, e.g. "Go"
+// and "go", which would otherwise produce separate tag pages. Every file using
+// one of the variants is reported.
+func checkTagVariants(s *site.Site, r *Report) {
+	postTags := map[string][]string{}
+	for _, post := range s.Posts {
+		for _, tag := range post.Frontmatter.Tags {
+			postTags[tag] = append(postTags[tag], post.Path)
+		}
+	}
+	reportTagVariants(r, "post", postTags)
+
+	noteTags := map[string][]string{}
+	for _, note := range s.Notes {
+		for _, tag := range note.Frontmatter.Tags {
+			noteTags[tag] = append(noteTags[tag], note.Path)
+		}
+	}
+	reportTagVariants(r, "note", noteTags)
+}
+
+func reportTagVariants(r *Report, kind string, tags map[string][]string) {
+	variants := map[string][]string{}
+	for tag := range tags {
+		key := normalizeTag(tag)
+		variants[key] = append(variants[key], tag)
+	}
+
+	for _, group := range variants {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Strings(group)
+		for _, tag := range group {
+			for _, path := range tags[tag] {
+				r.Errorf("tag-variant", path, "%s tag %q has variants that differ only by case or spacing: %s", kind, tag, strings.Join(quoteAll(group), ", "))
+			}
+		}
+	}
+}
+
+func normalizeTag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, tag)
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = `"` + v + `"`
+	}
+	return quoted
+}
+
+// checkTitleLength reports post and note titles longer than the recommended
+// maximum.
+func checkTitleLength(s *site.Site, r *Report) {
+	for _, post := range s.Posts {
+		checkTitle(r, post.Path, post.Frontmatter.Title)
+	}
+	for _, note := range s.Notes {
+		checkTitle(r, note.Path, note.Frontmatter.Title)
+	}
+}
+
+func checkTitle(r *Report, path, title string) {
+	if n := len([]rune(title)); n > MaxTitleLength {
+		r.Warnf("title-length", path, "title is %d characters; recommended maximum is %d", n, MaxTitleLength)
+	}
+}
+
+// checkDescriptionLength reports post descriptions outside of the recommended
+// length range.
+func checkDescriptionLength(s *site.Site, r *Report) {
+	for _, post := range s.Posts {
+		n := len([]rune(post.Frontmatter.Description))
+		if n < MinDescriptionLength || n > MaxDescriptionLength {
+			r.Warnf("description-length", post.Path, "description is %d characters; recommended length is %d-%d", n, MinDescriptionLength, MaxDescriptionLength)
+		}
+	}
+}
+
+// checkImageAltText reports images without an alt attribute. An empty alt
+// attribute marks an image as decorative, so it isn't reported.
+func checkImageAltText(s *site.Site, r *Report) {
+	for _, doc := range documents(s) {
+		for _, img := range htmlutil.Images(doc.content) {
+			if !img.HasAlt {
+				r.Warnf("image-alt", doc.path, "image %q is missing alt text", img.Src)
+			}
+		}
+	}
+}
+
+// checkEmptySeries reports series that do not contain any posts.
+func checkEmptySeries(s *site.Site, r *Report) {
+	for _, series := range s.Series {
+		if len(series.Posts) == 0 {
+			r.Warnf("empty-series", seriesPath(s, series), "series %q has no posts", series.Slug)
+		}
+	}
+}
+
+// checkDraftOnlySeries reports series where every post is a draft. These
+// series will be empty in production builds.
+func checkDraftOnlySeries(s *site.Site, r *Report) {
+	for _, series := range s.Series {
+		if len(series.Posts) == 0 {
+			continue
+		}
+
+		drafts := 0
+		for _, post := range series.Posts {
+			if post.Frontmatter.Draft {
+				drafts++
+			}
+		}
+
+		if drafts == len(series.Posts) {
+			r.Warnf("draft-only-series", seriesPath(s, series), "series %q only contains drafts and will be empty when published", series.Slug)
+		}
+	}
+}
+
+// checkRawHTML reports content where raw HTML was omitted from the rendered
+// output.
+func checkRawHTML(s *site.Site, r *Report) {
+	for _, doc := range documents(s) {
+		if n := strings.Count(doc.content, rawHTMLMarker); n > 0 {
+			r.Warnf("raw-html", doc.path, "%d raw HTML block(s) were omitted from the rendered output", n)
+		}
+	}
+}
+
+// checkDiagrams reports diagrams that failed to render. Diagrams are only
+// rendered if the site was loaded with a diagram cache, and failures are only
+// left in the content if it was loaded with ShowDiagramErrors set.
+func checkDiagrams(s *site.Site, r *Report) {
+	for _, doc := range documents(s) {
+		for _, msg := range diagram.Errors(doc.content) {
+			r.Errorf("diagram", doc.path, "%s", msg)
+		}
+	}
+}
+
+// checkWikiLinks reports wiki links whose targets don't match any note or
+// post, and embeds whose targets don't match any note or heading.
+func checkWikiLinks(s *site.Site, r *Report) {
+	for _, note := range s.Notes {
+		for _, target := range note.UnresolvedLinks {
+			r.Errorf("wiki-link", note.Path, "wiki link [[%s]] could not be resolved", target)
+		}
+	}
+	for _, post := range s.Posts {
+		for _, target := range post.UnresolvedLinks {
+			r.Errorf("wiki-link", post.Path, "wiki link [[%s]] could not be resolved", target)
+		}
+	}
+}
+
+func seriesPath(s *site.Site, series *site.Series) string {
+	return filepath.Join(s.Dir, "posts", series.Slug, "index.yaml")
+}
@@ -3,11 +3,15 @@ package compiler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 
+	"github.com/haleyrc/stele/internal/images"
 	"github.com/haleyrc/stele/internal/site"
 )
 
@@ -18,6 +22,10 @@ type Compiler struct {
 
 	// The renderer to use for rendering content.
 	Renderer site.Renderer
+
+	// How broken internal links in the compiled output are handled. Defaults
+	// to LinkCheckOff.
+	LinkCheck LinkCheckMode
 }
 
 // NewCompiler creates a new compiler for the given site and renderer.
@@ -52,6 +60,10 @@ func (c *Compiler) Compile(ctx context.Context, dstDir, srcDir string) error {
 		if err := c.renderNoteTagsToFiles(ctx, dstDir); err != nil {
 			return fmt.Errorf("build: %w", err)
 		}
+
+		if err := c.renderNoteGraphToFiles(ctx, dstDir); err != nil {
+			return fmt.Errorf("build: %w", err)
+		}
 	}
 
 	if err := c.renderPostsToFiles(ctx, dstDir); err != nil {
@@ -78,6 +90,44 @@ func (c *Compiler) Compile(ctx context.Context, dstDir, srcDir string) error {
 		return fmt.Errorf("build: %w", err)
 	}
 
+	if err := c.renderSitemapToFile(ctx, dstDir); err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+
+	if err := c.renderSearchToFiles(ctx, dstDir); err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+
+	if err := c.copyImagesToFiles(dstDir, srcDir); err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+
+	if err := c.checkLinks(dstDir); err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Compiler) checkLinks(dir string) error {
+	if c.LinkCheck == LinkCheckOff {
+		return nil
+	}
+
+	log.Printf("Checking internal links...")
+	broken, err := CheckLinks(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range broken {
+		log.Printf("Broken link: %s", link)
+	}
+
+	if len(broken) > 0 && c.LinkCheck == LinkCheckError {
+		return fmt.Errorf("check links: found %d broken internal link(s)", len(broken))
+	}
+
 	return nil
 }
 
@@ -126,10 +176,34 @@ func (c *Compiler) renderToFile(ctx context.Context, path string, renderFn func(
 	return renderFn(ctx, f)
 }
 
+// renderPagesToFiles renders each page of a paginated list with renderFn.
+// The first page is written to the file for the list's base URL and later
+// pages to {base}/page/{n}.html.
+func (c *Compiler) renderPagesToFiles(ctx context.Context, dir string, pages []site.Page, renderFn func(context.Context, *os.File, site.Page) error) error {
+	for _, page := range pages {
+		url := page.URL(page.Number)
+		if url == "/" {
+			url = "/index"
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(url)+".html")
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return err
+		}
+
+		if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
+			return renderFn(ctx, w, page)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (c *Compiler) renderIndexToFile(ctx context.Context, dir string) error {
-	path := filepath.Join(dir, "index.html")
-	return c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
-		return c.Renderer.RenderIndex(ctx, w, c.Site)
+	return c.renderPagesToFiles(ctx, dir, c.Site.HomePages(), func(ctx context.Context, w *os.File, page site.Page) error {
+		return c.Renderer.RenderIndex(ctx, w, c.Site, page)
 	})
 }
 
@@ -159,11 +233,32 @@ func (c *Compiler) renderNotesToFiles(ctx context.Context, dir string) error {
 
 	for _, note := range c.Site.Notes {
 		path := filepath.Join(dir, "notes", note.Slug+".html")
+
+		// Create subdirectory for nested notes if needed
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return fmt.Errorf("render notes: %w", err)
+		}
+
 		if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
 			return c.Renderer.RenderNote(ctx, w, c.Site, note)
 		}); err != nil {
 			return fmt.Errorf("render notes: %w", err)
 		}
+
+		if !note.Frontmatter.History {
+			continue
+		}
+
+		path = filepath.Join(dir, "notes", note.Slug, "history.html")
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return fmt.Errorf("render notes: %w", err)
+		}
+
+		if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
+			return c.Renderer.RenderNoteHistory(ctx, w, c.Site, note)
+		}); err != nil {
+			return fmt.Errorf("render notes: %w", err)
+		}
 	}
 
 	return nil
@@ -190,6 +285,25 @@ func (c *Compiler) renderNoteTagsToFiles(ctx context.Context, dir string) error
 	return nil
 }
 
+func (c *Compiler) renderNoteGraphToFiles(ctx context.Context, dir string) error {
+	path := filepath.Join(dir, "notes", "graph.json")
+	graph := c.Site.NoteGraph()
+	if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
+		return c.Renderer.RenderNoteGraph(ctx, w, c.Site, graph)
+	}); err != nil {
+		return fmt.Errorf("render note graph: %w", err)
+	}
+
+	path = filepath.Join(dir, "notes", "graph.html")
+	if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
+		return c.Renderer.RenderNoteGraphPage(ctx, w, c.Site)
+	}); err != nil {
+		return fmt.Errorf("render note graph: %w", err)
+	}
+
+	return nil
+}
+
 func (c *Compiler) renderPostsToFiles(ctx context.Context, dir string) error {
 	for _, post := range c.Site.Posts {
 		// Create subdirectory for series posts if needed
@@ -232,9 +346,9 @@ func (c *Compiler) renderArchiveToFiles(ctx context.Context, dir string) error {
 
 	postsByYear := c.Site.Posts.IndexByYear()
 	for _, entry := range postsByYear {
-		path := filepath.Join(dir, "archive", entry.Key+".html")
-		if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
-			return c.Renderer.RenderArchivePage(ctx, w, c.Site, entry.Key, entry.Posts)
+		pages := c.Site.Paginate("/archive/"+entry.Key, entry.Posts)
+		if err := c.renderPagesToFiles(ctx, dir, pages, func(ctx context.Context, w *os.File, page site.Page) error {
+			return c.Renderer.RenderArchivePage(ctx, w, c.Site, entry.Key, page)
 		}); err != nil {
 			return fmt.Errorf("render archive: %w", err)
 		}
@@ -253,9 +367,9 @@ func (c *Compiler) renderTagsToFiles(ctx context.Context, dir string) error {
 
 	postsByTag := c.Site.Posts.IndexByTag()
 	for _, entry := range postsByTag {
-		path := filepath.Join(dir, "tags", entry.Key+".html")
-		if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
-			return c.Renderer.RenderTagPage(ctx, w, c.Site, entry.Key, entry.Posts)
+		pages := c.Site.Paginate("/tags/"+entry.Key, entry.Posts)
+		if err := c.renderPagesToFiles(ctx, dir, pages, func(ctx context.Context, w *os.File, page site.Page) error {
+			return c.Renderer.RenderTagPage(ctx, w, c.Site, entry.Key, page)
 		}); err != nil {
 			return fmt.Errorf("render tags: %w", err)
 		}
@@ -279,3 +393,92 @@ func (c *Compiler) renderRSSToFile(ctx context.Context, dir string) error {
 		return c.Renderer.RenderRSSFeed(ctx, w, c.Site, feed)
 	})
 }
+
+func (c *Compiler) renderSitemapToFile(ctx context.Context, dir string) error {
+	path := filepath.Join(dir, "sitemap.xml")
+	sitemap := c.Site.Sitemap()
+	return c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
+		return c.Renderer.RenderSitemap(ctx, w, c.Site, sitemap)
+	})
+}
+
+func (c *Compiler) renderSearchToFiles(ctx context.Context, dir string) error {
+	path := filepath.Join(dir, "search.json")
+	index := c.Site.SearchIndex()
+	if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
+		return c.Renderer.RenderSearchIndex(ctx, w, c.Site, index)
+	}); err != nil {
+		return fmt.Errorf("render search: %w", err)
+	}
+
+	path = filepath.Join(dir, "search.html")
+	if err := c.renderToFile(ctx, path, func(ctx context.Context, w *os.File) error {
+		return c.Renderer.RenderSearchPage(ctx, w, c.Site)
+	}); err != nil {
+		return fmt.Errorf("render search: %w", err)
+	}
+
+	return nil
+}
+
+// copyImagesToFiles copies the images directory of the site, if there is one,
+// along with the generated variants of any processed images.
+func (c *Compiler) copyImagesToFiles(dir, srcDir string) error {
+	imagesDir := filepath.Join(srcDir, images.Dir)
+	if _, err := os.Stat(imagesDir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	err := filepath.WalkDir(imagesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		return copyFile(filepath.Join(dir, rel), path)
+	})
+	if err != nil {
+		return fmt.Errorf("copy images: %w", err)
+	}
+
+	for _, img := range c.Site.Images {
+		for _, v := range img.Variants {
+			if v.URL == img.Src {
+				continue
+			}
+			if err := copyFile(filepath.Join(dir, filepath.FromSlash(v.URL)), v.Path); err != nil {
+				return fmt.Errorf("copy images: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func copyFile(dst, src string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src) // #nosec G304 - Copying from the user-specified site directory is intentional
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst) // #nosec G304 - User-controlled output directory is intentional
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	log.Printf("Writing %s...", dst)
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
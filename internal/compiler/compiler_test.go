@@ -9,9 +9,11 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/haleyrc/assert"
 	"github.com/haleyrc/stele/internal/compiler"
+	"github.com/haleyrc/stele/internal/images"
 	"github.com/haleyrc/stele/internal/site"
 )
 
@@ -59,12 +61,12 @@ func (m *mockRenderer) RenderArchiveIndex(ctx context.Context, w io.Writer, site
 	return m.writeContent(w, "Archive Index")
 }
 
-func (m *mockRenderer) RenderArchivePage(ctx context.Context, w io.Writer, s *site.Site, year string, posts site.Posts) error {
+func (m *mockRenderer) RenderArchivePage(ctx context.Context, w io.Writer, s *site.Site, year string, page site.Page) error {
 	m.track("RenderArchivePage")
 	return m.writeContent(w, fmt.Sprintf("Archive %s", year))
 }
 
-func (m *mockRenderer) RenderIndex(ctx context.Context, w io.Writer, site *site.Site) error {
+func (m *mockRenderer) RenderIndex(ctx context.Context, w io.Writer, site *site.Site, page site.Page) error {
 	m.track("RenderIndex")
 	return m.writeContent(w, "Index Page")
 }
@@ -80,6 +82,22 @@ func (m *mockRenderer) RenderNote(ctx context.Context, w io.Writer, s *site.Site
 	return m.writeContent(w, fmt.Sprintf("Note: %s", note.Slug))
 }
 
+func (m *mockRenderer) RenderNoteGraph(ctx context.Context, w io.Writer, s *site.Site, graph *site.NoteGraph) error {
+	m.track("RenderNoteGraph")
+	_, err := w.Write([]byte(`{"nodes":[],"edges":[]}`))
+	return err
+}
+
+func (m *mockRenderer) RenderNoteGraphPage(ctx context.Context, w io.Writer, site *site.Site) error {
+	m.track("RenderNoteGraphPage")
+	return m.writeContent(w, "Note Graph")
+}
+
+func (m *mockRenderer) RenderNoteHistory(ctx context.Context, w io.Writer, s *site.Site, note *site.Note) error {
+	m.track("RenderNoteHistory")
+	return m.writeContent(w, fmt.Sprintf("Note History: %s", note.Slug))
+}
+
 func (m *mockRenderer) RenderNotesIndex(ctx context.Context, w io.Writer, site *site.Site) error {
 	m.track("RenderNotesIndex")
 	return m.writeContent(w, "Notes Index")
@@ -106,17 +124,33 @@ func (m *mockRenderer) RenderRSSFeed(ctx context.Context, w io.Writer, s *site.S
 	return err
 }
 
+func (m *mockRenderer) RenderSearchIndex(ctx context.Context, w io.Writer, s *site.Site, index *site.SearchIndex) error {
+	m.track("RenderSearchIndex")
+	_, err := w.Write([]byte(`{"documents":[],"terms":{}}`))
+	return err
+}
+
+func (m *mockRenderer) RenderSearchPage(ctx context.Context, w io.Writer, site *site.Site) error {
+	m.track("RenderSearchPage")
+	return m.writeContent(w, "Search")
+}
+
 func (m *mockRenderer) RenderSeriesIndex(ctx context.Context, w io.Writer, s *site.Site, series *site.Series) error {
 	m.track("RenderSeriesIndex")
 	return m.writeContent(w, fmt.Sprintf("Series: %s", series.Slug))
 }
 
+func (m *mockRenderer) RenderSitemap(ctx context.Context, w io.Writer, s *site.Site, sitemap *site.Sitemap) error {
+	m.track("RenderSitemap")
+	return m.writeContent(w, "Sitemap")
+}
+
 func (m *mockRenderer) RenderTagIndex(ctx context.Context, w io.Writer, site *site.Site) error {
 	m.track("RenderTagIndex")
 	return m.writeContent(w, "Tag Index")
 }
 
-func (m *mockRenderer) RenderTagPage(ctx context.Context, w io.Writer, s *site.Site, tag string, posts site.Posts) error {
+func (m *mockRenderer) RenderTagPage(ctx context.Context, w io.Writer, s *site.Site, tag string, page site.Page) error {
 	m.track("RenderTagPage")
 	return m.writeContent(w, fmt.Sprintf("Tag: %s", tag))
 }
@@ -224,6 +258,64 @@ func TestCompiler_CompleteBuild(t *testing.T) {
 	assertFileExists(t, "series index", filepath.Join(outputDir, "go-basics.html"))
 }
 
+func TestCompiler_Pagination(t *testing.T) {
+	testSite, err := site.New("../site/testdata", site.SiteOptions{})
+	assert.OK(t, err).Fatal()
+	testSite.Config.PageSize = 2
+
+	renderer := newMockRenderer()
+	c := compiler.NewCompiler(renderer, testSite)
+
+	outputDir := t.TempDir()
+	err = c.Compile(context.Background(), outputDir, "../site/testdata")
+	assert.OK(t, err).Fatal()
+
+	assertFileExists(t, "first home page", filepath.Join(outputDir, "index.html"))
+	assertFileExists(t, "last home page", filepath.Join(outputDir, "page", "3.html"))
+	assertFileNotExists(t, "numbered first home page", filepath.Join(outputDir, "page", "1.html"))
+	assertFileExists(t, "archive page", filepath.Join(outputDir, "archive", "2025", "page", "2.html"))
+	assertFileExists(t, "tag page", filepath.Join(outputDir, "tags", "go", "page", "2.html"))
+}
+
+func TestCompiler_CopiesImages(t *testing.T) {
+	testSite, err := site.New("../site/testdata", site.SiteOptions{})
+	assert.OK(t, err).Fatal()
+
+	srcDir := t.TempDir()
+	for _, path := range []string{
+		filepath.Join(srcDir, "images", "diagram.svg"),
+		filepath.Join(srcDir, "images", "2025", "photo.png"),
+		filepath.Join(srcDir, ".stele", "images", "abc", "480.png"),
+	} {
+		err := os.MkdirAll(filepath.Dir(path), 0750)
+		assert.OK(t, err).Fatal()
+		err = os.WriteFile(path, []byte(path), 0600)
+		assert.OK(t, err).Fatal()
+	}
+	testSite.Images = []*images.Image{{
+		Src: "/images/2025/photo.png",
+		Variants: []images.Variant{
+			{URL: "/images/2025/photo-480w.png", Path: filepath.Join(srcDir, ".stele", "images", "abc", "480.png")},
+			{URL: "/images/2025/photo.png", Path: filepath.Join(srcDir, "images", "2025", "photo.png")},
+		},
+	}}
+
+	renderer := newMockRenderer()
+	c := compiler.NewCompiler(renderer, testSite)
+
+	outputDir := t.TempDir()
+	err = c.Compile(context.Background(), outputDir, srcDir)
+	assert.OK(t, err).Fatal()
+
+	assertFileExists(t, "unprocessed image", filepath.Join(outputDir, "images", "diagram.svg"))
+	assertFileExists(t, "original image", filepath.Join(outputDir, "images", "2025", "photo.png"))
+	assertFileNotExists(t, "cache", filepath.Join(outputDir, ".stele"))
+
+	variant, err := os.ReadFile(filepath.Join(outputDir, "images", "2025", "photo-480w.png"))
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "variant", filepath.Join(srcDir, ".stele", "images", "abc", "480.png"), string(variant))
+}
+
 func TestCompiler_ProductionBuildExcludesDrafts(t *testing.T) {
 	// Load site WITHOUT drafts (production mode)
 	testSite, err := site.New("../site/testdata", site.SiteOptions{IncludeDrafts: false})
@@ -341,6 +433,7 @@ func TestCompiler_RendererCalledForAllPages(t *testing.T) {
 	testSite, err := site.New("../site/testdata", site.SiteOptions{
 		IncludeDrafts:   true,
 		NotesExperiment: true,
+		Now:             time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
 	})
 	assert.OK(t, err).Fatal()
 
@@ -360,8 +453,13 @@ func TestCompiler_RendererCalledForAllPages(t *testing.T) {
 	assert.Equal(t, "RenderArchiveIndex called once", 1, renderer.getCalls("RenderArchiveIndex"))
 	assert.Equal(t, "RenderTagIndex called once", 1, renderer.getCalls("RenderTagIndex"))
 	assert.Equal(t, "RenderNoteTagIndex called once", 1, renderer.getCalls("RenderNoteTagIndex"))
+	assert.Equal(t, "RenderNoteGraph called once", 1, renderer.getCalls("RenderNoteGraph"))
+	assert.Equal(t, "RenderNoteGraphPage called once", 1, renderer.getCalls("RenderNoteGraphPage"))
 	assert.Equal(t, "RenderManifest called once", 1, renderer.getCalls("RenderManifest"))
 	assert.Equal(t, "RenderRSSFeed called once", 1, renderer.getCalls("RenderRSSFeed"))
+	assert.Equal(t, "RenderSitemap called once", 1, renderer.getCalls("RenderSitemap"))
+	assert.Equal(t, "RenderSearchIndex called once", 1, renderer.getCalls("RenderSearchIndex"))
+	assert.Equal(t, "RenderSearchPage called once", 1, renderer.getCalls("RenderSearchPage"))
 
 	// Verify note pages rendered (3 notes in testdata)
 	assert.Equal(t, "RenderNote called for each note", 3, renderer.getCalls("RenderNote"))
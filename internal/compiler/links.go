@@ -0,0 +1,196 @@
+package compiler
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haleyrc/stele/internal/htmlutil"
+)
+
+// LinkCheckMode controls how the compiler handles broken internal links in
+// the compiled output.
+type LinkCheckMode int
+
+const (
+	// LinkCheckOff disables internal link checking.
+	LinkCheckOff LinkCheckMode = iota
+
+	// LinkCheckWarn logs broken internal links without failing the build.
+	LinkCheckWarn
+
+	// LinkCheckError logs broken internal links and fails the build.
+	LinkCheckError
+)
+
+// ParseLinkCheckMode converts a mode name ("off", "warn", or "error") into a
+// LinkCheckMode.
+func ParseLinkCheckMode(s string) (LinkCheckMode, error) {
+	switch strings.ToLower(s) {
+	case "off":
+		return LinkCheckOff, nil
+	case "warn":
+		return LinkCheckWarn, nil
+	case "error":
+		return LinkCheckError, nil
+	default:
+		return LinkCheckOff, fmt.Errorf("parse link check mode: unknown mode: %s", s)
+	}
+}
+
+// BrokenLink describes an internal link in the compiled output that does not
+// resolve to a generated file or fragment.
+type BrokenLink struct {
+	// The output file containing the link, relative to the output directory.
+	Page string
+
+	// The link target exactly as written in the page.
+	Target string
+
+	// Why the link could not be resolved.
+	Reason string
+}
+
+// String returns a human-readable description of the broken link.
+func (bl BrokenLink) String() string {
+	return fmt.Sprintf("%s: %s (%s)", bl.Page, bl.Target, bl.Reason)
+}
+
+// CheckLinks walks the compiled output in dir and returns every internal href
+// or src reference that does not resolve to a generated file. Links with a
+// fragment are also checked against the ids defined in the target page.
+// External links are ignored.
+func CheckLinks(dir string) ([]BrokenLink, error) {
+	outputs := map[string]bool{}
+	pages := map[string]string{}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		outputs[rel] = true
+
+		if filepath.Ext(p) == ".html" {
+			contents, err := os.ReadFile(p) // #nosec G304 - Walking the compiler's own output directory
+			if err != nil {
+				return err
+			}
+			pages[rel] = string(contents)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("check links: %w", err)
+	}
+
+	ids := map[string]map[string]bool{}
+	idsFor := func(page string) map[string]bool {
+		if _, ok := ids[page]; !ok {
+			ids[page] = htmlutil.IDs(pages[page])
+		}
+		return ids[page]
+	}
+
+	var broken []BrokenLink
+	for page, contents := range pages {
+		for _, link := range htmlutil.Links(contents) {
+			target, fragment, ok := resolveLink(page, link.URL)
+			if !ok {
+				continue
+			}
+
+			if target == "" {
+				target = page
+			} else if resolved := resolveOutput(outputs, target); resolved != "" {
+				target = resolved
+			} else {
+				broken = append(broken, BrokenLink{Page: page, Target: link.URL, Reason: "no such page"})
+				continue
+			}
+
+			if fragment == "" || fragment == "top" || !strings.HasSuffix(target, ".html") {
+				continue
+			}
+
+			if !idsFor(target)[fragment] {
+				broken = append(broken, BrokenLink{Page: page, Target: link.URL, Reason: "no such anchor"})
+			}
+		}
+	}
+
+	sort.Slice(broken, func(i, j int) bool {
+		if broken[i].Page != broken[j].Page {
+			return broken[i].Page < broken[j].Page
+		}
+		return broken[i].Target < broken[j].Target
+	})
+
+	return broken, nil
+}
+
+// resolveLink resolves a link found on page into a path relative to the output
+// directory and a fragment. An empty path means the link refers to the page
+// itself. The final return value is false for links that should not be
+// checked, such as external URLs and dev server endpoints.
+func resolveLink(page, link string) (string, string, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", false
+	}
+
+	if u.Scheme != "" || u.Host != "" {
+		return "", "", false
+	}
+
+	if u.Path == "" {
+		return "", u.Fragment, true
+	}
+
+	// Pages are served without their .html extension, so relative links are
+	// resolved against the extensionless URL.
+	base := &url.URL{Path: "/" + strings.TrimSuffix(page, ".html")}
+	resolved := base.ResolveReference(&url.URL{Path: u.Path}).Path
+
+	// The live reload script is only served by the dev server.
+	if strings.HasPrefix(resolved, "/__dev__/") {
+		return "", "", false
+	}
+
+	return strings.TrimPrefix(resolved, "/"), u.Fragment, true
+}
+
+// resolveOutput returns the generated file that would be served for target,
+// or an empty string if there is none.
+func resolveOutput(outputs map[string]bool, target string) string {
+	target = strings.TrimSuffix(target, "/")
+
+	candidates := []string{
+		path.Join(target, "index.html"),
+	}
+	if target != "" {
+		candidates = append([]string{target, target + ".html"}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		if outputs[candidate] {
+			return candidate
+		}
+	}
+
+	return ""
+}
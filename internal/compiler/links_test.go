@@ -0,0 +1,69 @@
+package compiler_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/compiler"
+)
+
+func writeOutput(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	dir := t.TempDir()
+	writeOutput(t, dir, "index.html", `
+		<link rel="alternate" href="/rss.xml">
+		<script src="/__dev__/reload.js"></script>
+		<a href="/">Home</a>
+		<a href="/posts/first">First</a>
+		<a href="/posts/first#usage">Usage</a>
+		<a href="/posts/first#missing">Missing anchor</a>
+		<a href="/posts/renamed">Renamed</a>
+		<a href="https://example.com/nowhere">External</a>
+		<a href="#">Top</a>
+		<a href="#footer">Footer</a>
+		<footer id="footer"></footer>
+	`)
+	writeOutput(t, dir, "rss.xml", `<rss></rss>`)
+	writeOutput(t, dir, "posts/first.html", `
+		<h2 id="usage">Usage</h2>
+		<a href="second">Second</a>
+		<a href="series/part-1">Part 1</a>
+		<img src="../images/missing.png">
+	`)
+	writeOutput(t, dir, "posts/second.html", `<a href="#nope">Nope</a>`)
+	writeOutput(t, dir, "posts/series/part-1.html", `<a href="../first#usage">Back</a>`)
+
+	broken, err := compiler.CheckLinks(dir)
+	assert.OK(t, err).Fatal()
+
+	want := []compiler.BrokenLink{
+		{Page: "index.html", Target: "/posts/first#missing", Reason: "no such anchor"},
+		{Page: "index.html", Target: "/posts/renamed", Reason: "no such page"},
+		{Page: "posts/first.html", Target: "../images/missing.png", Reason: "no such page"},
+		{Page: "posts/second.html", Target: "#nope", Reason: "no such anchor"},
+	}
+	assert.SliceEqual(t, "broken links", want, broken)
+}
+
+func TestParseLinkCheckMode(t *testing.T) {
+	mode, err := compiler.ParseLinkCheckMode("warn")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "mode", compiler.LinkCheckWarn, mode)
+
+	_, err = compiler.ParseLinkCheckMode("sometimes")
+	if err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
@@ -0,0 +1,152 @@
+// Package diagram renders diagrams written in fenced code blocks to inline SVG.
+//
+// Diagrams are drawn with ASCII art in code blocks tagged with the goat
+// language, as understood by github.com/bep/goat.
+package diagram
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bep/goat"
+)
+
+// Language is the language of the fenced code blocks rendered as diagrams.
+const Language = "goat"
+
+// cacheVersion is included in cache keys so that changes to how diagrams are
+// rendered invalidate previously cached diagrams.
+const cacheVersion = 1
+
+// errorPattern matches the message in an error box.
+var errorPattern = regexp.MustCompile(`<div class="diagram-error" role="alert"><p><strong>Diagram error:</strong> (.*?)</p>`)
+
+// Renderer renders diagrams, caching the SVG for each one by the hash of its
+// source.
+type Renderer struct {
+	// The directory in which rendered diagrams are cached.
+	CacheDir string
+
+	// Whether diagrams that fail to render are replaced with a message
+	// describing the error instead of failing to render the document.
+	ShowErrors bool
+
+	// The number of diagrams rendered so far.
+	count int
+}
+
+// NewRenderer creates a new renderer that caches diagrams in cacheDir.
+func NewRenderer(cacheDir string) *Renderer {
+	return &Renderer{CacheDir: cacheDir}
+}
+
+// Count returns the number of diagrams rendered so far.
+func (r *Renderer) Count() int {
+	return r.count
+}
+
+// HTML returns the diagram described by src, which starts on the given line
+// of its document, as an SVG element in a div. If the diagram fails to
+// render, an error identifying it is returned, or if ShowErrors is set, the
+// error is logged and the HTML describes the error followed by the source of
+// the diagram.
+func (r *Renderer) HTML(src string, line int) (string, error) {
+	svg, err := r.Render(src)
+	if err == nil {
+		return `<div class="diagram">` + svg + `</div>`, nil
+	}
+
+	err = fmt.Errorf("line %d: diagram: %w", line, err)
+	if !r.ShowErrors {
+		return "", err
+	}
+	log.Printf("WARN: %v", err)
+	return errorBox(err, src), nil
+}
+
+// Render returns the diagram described by src as an SVG element, or loads it
+// from the cache if it has been rendered before.
+func (r *Renderer) Render(src string) (string, error) {
+	path := filepath.Join(r.CacheDir, cacheKey(src)+".svg")
+	if data, err := os.ReadFile(path); err == nil { // #nosec G304 - Cache path is derived from a hash
+		r.count++
+		return string(data), nil
+	}
+
+	svg, err := render(src)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(r.CacheDir, 0750); err != nil {
+		return "", fmt.Errorf("render: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(svg), 0600); err != nil {
+		return "", fmt.Errorf("render: %w", err)
+	}
+
+	r.count++
+	return svg, nil
+}
+
+// render renders src without consulting the cache.
+func render(src string) (svg string, err error) {
+	src = strings.Trim(src, "\n")
+	if strings.TrimSpace(src) == "" {
+		return "", errors.New("render: empty diagram")
+	}
+
+	// Tabs have no fixed width, so the diagram can't be laid out on a grid.
+	for i, line := range strings.Split(src, "\n") {
+		if col := strings.IndexByte(line, '\t'); col >= 0 {
+			return "", fmt.Errorf("render: line %d, column %d: tabs aren't allowed, use spaces to align the diagram", i+1, col+1)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("render: %v", r)
+		}
+	}()
+
+	d := goat.BuildSVG(strings.NewReader(src + "\n"))
+	return fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" role="img" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Menlo,Lucida Console,monospace">`+"\n%s</svg>",
+		d.Width, d.Height, d.Width, d.Height, d.Body,
+	), nil
+}
+
+// Errors returns the message of each diagram in content that was replaced
+// with an error because it failed to render.
+func Errors(content string) []string {
+	var errs []string
+	for _, m := range errorPattern.FindAllStringSubmatch(content, -1) {
+		errs = append(errs, html.UnescapeString(m[1]))
+	}
+	return errs
+}
+
+// errorBox returns a message describing err followed by the source of the
+// diagram that caused it.
+func errorBox(err error, src string) string {
+	return `<div class="diagram-error" role="alert"><p><strong>Diagram error:</strong> ` +
+		html.EscapeString(err.Error()) +
+		`</p><pre><code>` + html.EscapeString(src) + `</code></pre></div>`
+}
+
+// cacheKey returns the name of the cache file for a diagram with the given
+// source.
+func cacheKey(src string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n", cacheVersion)
+	h.Write([]byte(src))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
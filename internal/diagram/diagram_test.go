@@ -0,0 +1,76 @@
+package diagram_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/diagram"
+)
+
+func TestRenderer_HTML(t *testing.T) {
+	cacheDir := filepath.Join(t.TempDir(), "diagrams")
+	r := diagram.NewRenderer(cacheDir)
+
+	src := "+---+\n| a |-->\n+---+\n"
+	got, err := r.HTML(src, 3)
+	assert.OK(t, err).Fatal()
+
+	assert.True(t, "diagram", strings.HasPrefix(got, "<div class=\"diagram\"><svg "))
+	assert.True(t, "end", strings.HasSuffix(got, "</svg></div>"))
+	assert.Equal(t, "count", 1, r.Count())
+
+	// The cached diagram is used as-is, so changing it shows that the diagram
+	// wasn't rendered again.
+	entries, err := os.ReadDir(cacheDir)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "cache entries", 1, len(entries))
+	err = os.WriteFile(filepath.Join(cacheDir, entries[0].Name()), []byte("<svg>cached</svg>"), 0600)
+	assert.OK(t, err).Fatal()
+
+	got, err = diagram.NewRenderer(cacheDir).HTML(src, 3)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "cached diagram", `<div class="diagram"><svg>cached</svg></div>`, got)
+}
+
+func TestRenderer_HTML_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		src  string
+		want string
+	}{
+		"empty": {
+			src:  "\n",
+			want: "line 7: diagram: render: empty diagram",
+		},
+		"tabs": {
+			src:  "+--+\n|\t|\n",
+			want: "line 7: diagram: render: line 2, column 2: tabs aren't allowed",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := diagram.NewRenderer(t.TempDir())
+			_, err := r.HTML(tc.src, 7)
+			assert.Error(t, err, tc.want)
+		})
+	}
+}
+
+func TestRenderer_HTML_ShowErrors(t *testing.T) {
+	r := diagram.NewRenderer(t.TempDir())
+	r.ShowErrors = true
+
+	got, err := r.HTML("a\t<b\n", 2)
+	assert.OK(t, err).Fatal()
+
+	want := `<div class="diagram-error" role="alert"><p><strong>Diagram error:</strong> line 2: diagram: render: line 1, column 2: tabs aren&#39;t allowed, use spaces to align the diagram</p>` +
+		"<pre><code>a\t&lt;b\n</code></pre></div>"
+	assert.Equal(t, "content", want, got)
+
+	errs := diagram.Errors(got)
+	assert.Equal(t, "errors", 1, len(errs))
+	assert.Equal(t, "error", "line 2: diagram: render: line 1, column 2: tabs aren't allowed, use spaces to align the diagram", errs[0])
+}
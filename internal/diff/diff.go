@@ -0,0 +1,183 @@
+// Package diff computes line-based differences between two texts.
+package diff
+
+import "strings"
+
+// Op describes how a line changed between two texts.
+type Op int
+
+const (
+	// Equal lines appear in both texts.
+	Equal Op = iota
+
+	// Insert lines only appear in the new text.
+	Insert
+
+	// Delete lines only appear in the old text.
+	Delete
+)
+
+// Line is a single line of a diff.
+type Line struct {
+	// How the line changed.
+	Op Op
+
+	// The text of the line without its trailing newline.
+	Text string
+}
+
+// Lines returns the differences between old and new as a sequence of lines,
+// in order, with deletions listed before insertions where lines were
+// replaced. The result is based on a longest common subsequence, so it is the
+// shortest possible edit between the two texts.
+func Lines(old, new string) []Line {
+	a, b := split(old), split(new)
+
+	// Lines shared by the start and end of both texts are part of any
+	// longest common subsequence, so they're left out of the search for one.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, max(len(a), len(b)))
+	lines = appendLines(lines, Equal, a[:prefix])
+	lines = appendDiff(lines, a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	lines = appendLines(lines, Equal, a[len(a)-suffix:])
+
+	return deletionsFirst(lines)
+}
+
+// appendDiff appends the differences between a and b to lines. It uses
+// Hirschberg's algorithm, which finds a longest common subsequence by
+// splitting a in half and finding where the subsequence crosses the split, so
+// it only needs space proportional to the length of b.
+func appendDiff(lines []Line, a, b []string) []Line {
+	switch {
+	case len(a) == 0:
+		return appendLines(lines, Insert, b)
+	case len(b) == 0:
+		return appendLines(lines, Delete, a)
+	case len(a) == 1:
+		for j, text := range b {
+			if text == a[0] {
+				lines = appendLines(lines, Insert, b[:j])
+				lines = append(lines, Line{Op: Equal, Text: text})
+				return appendLines(lines, Insert, b[j+1:])
+			}
+		}
+		lines = append(lines, Line{Op: Delete, Text: a[0]})
+		return appendLines(lines, Insert, b)
+	}
+
+	mid := len(a) / 2
+	head := lcsLengths(a[:mid], b)
+	tail := lcsLengths(reversed(a[mid:]), reversed(b))
+
+	// Split b where the subsequences of the two halves of a are longest
+	// combined.
+	split, best := 0, -1
+	for j := range len(b) + 1 {
+		if n := head[j] + tail[len(b)-j]; n > best {
+			split, best = j, n
+		}
+	}
+
+	lines = appendDiff(lines, a[:mid], b[:split])
+	return appendDiff(lines, a[mid:], b[split:])
+}
+
+// lcsLengths returns the length of the longest common subsequence of a and
+// each prefix of b, indexed by the length of the prefix.
+func lcsLengths(a, b []string) []int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+// deletionsFirst reorders each run of changed lines so that deletions come
+// before insertions.
+func deletionsFirst(lines []Line) []Line {
+	sorted := make([]Line, 0, len(lines))
+	var inserted []Line
+	for _, line := range lines {
+		switch line.Op {
+		case Insert:
+			inserted = append(inserted, line)
+		case Delete:
+			sorted = append(sorted, line)
+		default:
+			sorted = append(sorted, inserted...)
+			sorted = append(sorted, line)
+			inserted = inserted[:0]
+		}
+	}
+	return append(sorted, inserted...)
+}
+
+// appendLines appends each of texts to lines with the given op.
+func appendLines(lines []Line, op Op, texts []string) []Line {
+	for _, text := range texts {
+		lines = append(lines, Line{Op: op, Text: text})
+	}
+	return lines
+}
+
+// reversed returns a reversed copy of s.
+func reversed(s []string) []string {
+	r := make([]string, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
+
+// Hunks groups the changed lines of a diff into hunks, each surrounded by up
+// to context unchanged lines. Changes separated by no more than twice the
+// context are merged into a single hunk. A diff without changes has no hunks.
+func Hunks(lines []Line, context int) [][]Line {
+	var hunks [][]Line
+
+	start, end := -1, -1
+	for i, line := range lines {
+		if line.Op == Equal {
+			continue
+		}
+
+		from, to := max(i-context, 0), min(i+context+1, len(lines))
+		if start >= 0 && from > end {
+			hunks = append(hunks, lines[start:end])
+			start = -1
+		}
+		if start < 0 {
+			start = from
+		}
+		end = to
+	}
+	if start >= 0 {
+		hunks = append(hunks, lines[start:end])
+	}
+
+	return hunks
+}
+
+// split breaks s into lines, ignoring a single trailing newline.
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
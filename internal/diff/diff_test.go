@@ -0,0 +1,132 @@
+package diff_test
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/diff"
+)
+
+// format renders lines in unified diff style for easy comparison.
+func format(lines []diff.Line) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		switch line.Op {
+		case diff.Equal:
+			sb.WriteString(" ")
+		case diff.Insert:
+			sb.WriteString("+")
+		case diff.Delete:
+			sb.WriteString("-")
+		}
+		sb.WriteString(line.Text)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func TestLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{name: "identical", old: "a\nb\n", new: "a\nb\n", want: " a\n b\n"},
+		{name: "empty old", old: "", new: "a\nb\n", want: "+a\n+b\n"},
+		{name: "empty new", old: "a\n", new: "", want: "-a\n"},
+		{name: "both empty", old: "", new: "", want: ""},
+		{name: "insertion", old: "a\nc\n", new: "a\nb\nc\n", want: " a\n+b\n c\n"},
+		{name: "deletion", old: "a\nb\nc\n", new: "a\nc\n", want: " a\n-b\n c\n"},
+		{name: "replacement", old: "a\nb\nc\n", new: "a\nx\nc\n", want: " a\n-b\n+x\n c\n"},
+		{name: "missing trailing newline", old: "a\nb", new: "a\nb\n", want: " a\n b\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, "diff", tc.want, format(diff.Lines(tc.old, tc.new)))
+		})
+	}
+}
+
+func TestLines_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	text := func() string {
+		var sb strings.Builder
+		for range rng.IntN(30) {
+			sb.WriteString(string(rune('a' + rng.IntN(4))))
+			sb.WriteString("\n")
+		}
+		return sb.String()
+	}
+
+	for range 500 {
+		old, new := text(), text()
+		lines := diff.Lines(old, new)
+
+		var gotOld, gotNew strings.Builder
+		equal := 0
+		for i, line := range lines {
+			assert.False(t, "deletion after insertion", i > 0 && lines[i-1].Op == diff.Insert && line.Op == diff.Delete)
+			if line.Op != diff.Insert {
+				gotOld.WriteString(line.Text + "\n")
+			}
+			if line.Op != diff.Delete {
+				gotNew.WriteString(line.Text + "\n")
+			}
+			if line.Op == diff.Equal {
+				equal++
+			}
+		}
+
+		assert.Equal(t, "old", old, gotOld.String())
+		assert.Equal(t, "new", new, gotNew.String())
+		assert.Equal(t, "shortest edit", lcsLength(old, new), equal)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of the lines
+// of a and b.
+func lcsLength(a, b string) int {
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	if a == "" {
+		x = nil
+	}
+	if b == "" {
+		y = nil
+	}
+
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+func TestHunks(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	new := "1\nx\n3\n4\n5\n6\n7\n8\n9\ny\n"
+
+	hunks := diff.Hunks(diff.Lines(old, new), 1)
+
+	assert.Equal(t, "hunk count", 2, len(hunks))
+	assert.Equal(t, "first hunk", " 1\n-2\n+x\n 3\n", format(hunks[0]))
+	assert.Equal(t, "second hunk", " 9\n-10\n+y\n", format(hunks[1]))
+
+	merged := diff.Hunks(diff.Lines(old, new), 4)
+	assert.Equal(t, "merged hunk count", 1, len(merged))
+
+	assert.Equal(t, "no changes", 0, len(diff.Hunks(diff.Lines(old, old), 3)))
+}
@@ -0,0 +1,238 @@
+// Package drafts moves posts between draft and published states by rewriting
+// their frontmatter in place.
+package drafts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/haleyrc/stele/internal/site"
+)
+
+// DateLayout is the layout used for publish dates that fall exactly on
+// midnight UTC, which is how date-only values are parsed.
+const DateLayout = "2006-01-02"
+
+const delimiter = "---"
+
+// Today returns the date of now in loc as midnight UTC, which Publish writes
+// as a date without a time.
+func Today(now time.Time, loc *time.Location) time.Time {
+	y, m, d := now.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Resolve returns the path to the post identified by target in the site rooted
+// at dir. The target may be a path to a markdown file or a post slug, including
+// series slugs such as "go-basics/variables".
+func Resolve(dir, target string) (string, error) {
+	if strings.HasSuffix(target, ".md") {
+		if _, err := os.Stat(target); err != nil {
+			return "", fmt.Errorf("resolve: %w", err)
+		}
+		return target, nil
+	}
+
+	path := filepath.Join(dir, "posts", filepath.FromSlash(target)+".md")
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("resolve: no post with slug %q", target)
+		}
+		return "", fmt.Errorf("resolve: %w", err)
+	}
+
+	return path, nil
+}
+
+// Publish marks the draft post at path as published on date.
+func Publish(path string, date time.Time) error {
+	if err := rewrite(path, func(fm *yaml.Node) error {
+		draft := value(fm, "draft")
+		if !isTrue(draft) {
+			return fmt.Errorf("post is not a draft")
+		}
+		setScalar(draft, "!!bool", "false")
+
+		formatted := date.Format(time.RFC3339)
+		if date.Equal(date.Truncate(24 * time.Hour)) {
+			formatted = date.Format(DateLayout)
+		}
+
+		if existing := value(fm, "date"); existing != nil {
+			setScalar(existing, "!!timestamp", formatted)
+		} else {
+			insertAfter(fm, "draft", "date", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: formatted})
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("publish: %w", err)
+	}
+
+	return nil
+}
+
+// Unpublish turns the published post at path back into a draft. The date and
+// updated date are removed since drafts must not have them.
+func Unpublish(path string) error {
+	if err := rewrite(path, func(fm *yaml.Node) error {
+		draft := value(fm, "draft")
+		if isTrue(draft) {
+			return fmt.Errorf("post is already a draft")
+		}
+
+		if draft != nil {
+			setScalar(draft, "!!bool", "true")
+		} else {
+			insertAfter(fm, "date", "draft", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		}
+		remove(fm, "date")
+		remove(fm, "updated")
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("unpublish: %w", err)
+	}
+
+	return nil
+}
+
+// rewrite parses the frontmatter of the markdown file at path, applies fn to
+// the top-level mapping, and writes the file back if the result is valid.
+// Key order and comments are preserved.
+func rewrite(path string, fn func(fm *yaml.Node) error) error {
+	contents, err := os.ReadFile(path) // #nosec G304 - User-specified markdown file is intentional
+	if err != nil {
+		return err
+	}
+
+	header, body, err := split(contents)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(header, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: frontmatter is not a mapping", path)
+	}
+
+	if err := fn(doc.Content[0]); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	header = buf.Bytes()
+
+	var fm site.PostFrontmatter
+	if err := yaml.Unmarshal(header, &fm); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := fm.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(delimiter + "\n")
+	out.Write(header)
+	out.WriteString(delimiter + "\n")
+	out.Write(body)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, out.Bytes(), info.Mode().Perm())
+}
+
+// split separates a markdown file into its YAML frontmatter and everything
+// after the closing delimiter.
+func split(contents []byte) ([]byte, []byte, error) {
+	lines := bytes.SplitAfter(contents, []byte("\n"))
+	if len(lines) == 0 || string(bytes.TrimSpace(lines[0])) != delimiter {
+		return nil, nil, fmt.Errorf("missing frontmatter")
+	}
+
+	offset := len(lines[0])
+	for _, line := range lines[1:] {
+		if string(bytes.TrimSpace(line)) == delimiter {
+			return contents[len(lines[0]):offset], contents[offset+len(line):], nil
+		}
+		offset += len(line)
+	}
+
+	return nil, nil, fmt.Errorf("unterminated frontmatter")
+}
+
+// value returns the value node for key in mapping, or nil if it is not present.
+func value(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// isTrue returns true if node is a boolean scalar set to true.
+func isTrue(node *yaml.Node) bool {
+	var b bool
+	return node != nil && node.Decode(&b) == nil && b
+}
+
+// setScalar replaces the value of node while keeping any attached comments.
+func setScalar(node *yaml.Node, tag, value string) {
+	node.Kind = yaml.ScalarNode
+	node.Tag = tag
+	node.Value = value
+	node.Style = 0
+	node.Content = nil
+}
+
+// insertAfter adds key with val to mapping immediately after the entry for
+// after, or at the end if after is not present.
+func insertAfter(mapping *yaml.Node, after, key string, val *yaml.Node) {
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+
+	at := len(mapping.Content)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == after {
+			at = i + 2
+			break
+		}
+	}
+
+	content := make([]*yaml.Node, 0, len(mapping.Content)+2)
+	content = append(content, mapping.Content[:at]...)
+	content = append(content, keyNode, val)
+	content = append(content, mapping.Content[at:]...)
+	mapping.Content = content
+}
+
+// remove deletes the entry for key from mapping if it is present.
+func remove(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package drafts_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/drafts"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+const draftPost = `---
+# The title is shown on the home page.
+title: "My Post"
+description: "A post about things"
+draft: true # Flip me when ready
+tags: ["go", "testing"]
+---
+
+# Hello
+
+Some content.
+`
+
+func writePost(t *testing.T, dir, slug, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "posts", filepath.FromSlash(slug)+".md")
+	err := os.MkdirAll(filepath.Dir(path), 0750)
+	assert.OK(t, err).Fatal()
+
+	err = os.WriteFile(path, []byte(contents), 0600)
+	assert.OK(t, err).Fatal()
+
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	contents, err := os.ReadFile(path)
+	assert.OK(t, err).Fatal()
+
+	return string(contents)
+}
+
+func TestPublish(t *testing.T) {
+	path := writePost(t, t.TempDir(), "my-post", draftPost)
+
+	err := drafts.Publish(path, time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC))
+	assert.OK(t, err).Fatal()
+
+	want := `---
+# The title is shown on the home page.
+title: "My Post"
+description: "A post about things"
+draft: false # Flip me when ready
+date: 2025-03-14
+tags: ["go", "testing"]
+---
+
+# Hello
+
+Some content.
+`
+	assert.Equal(t, "contents", want, readFile(t, path))
+
+	post, err := site.LoadPost(path)
+	assert.OK(t, err).Fatal()
+	assert.False(t, "draft", post.Frontmatter.Draft)
+	assert.Equal(t, "year", 2025, post.Frontmatter.Timestamp.Year())
+}
+
+func TestPublish_WithTime(t *testing.T) {
+	path := writePost(t, t.TempDir(), "my-post", draftPost)
+
+	err := drafts.Publish(path, time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC))
+	assert.OK(t, err).Fatal()
+
+	post, err := site.LoadPost(path)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "hour", 9, post.Frontmatter.Timestamp.Hour())
+}
+
+func TestPublish_NotADraft(t *testing.T) {
+	path := writePost(t, t.TempDir(), "my-post", draftPost)
+
+	err := drafts.Publish(path, time.Now())
+	assert.OK(t, err).Fatal()
+
+	err = drafts.Publish(path, time.Now())
+	assert.Error(t, err, "not a draft")
+}
+
+func TestUnpublish(t *testing.T) {
+	path := writePost(t, t.TempDir(), "my-post", `---
+title: "My Post"
+description: "A post about things"
+date: 2025-09-15T09:15:00Z
+tags: ["go"]
+---
+Content.
+`)
+
+	err := drafts.Unpublish(path)
+	assert.OK(t, err).Fatal()
+
+	want := `---
+title: "My Post"
+description: "A post about things"
+draft: true
+tags: ["go"]
+---
+Content.
+`
+	assert.Equal(t, "contents", want, readFile(t, path))
+
+	err = drafts.Unpublish(path)
+	assert.Error(t, err, "already a draft")
+}
+
+func TestPublishUnpublish_RoundTrip(t *testing.T) {
+	path := writePost(t, t.TempDir(), "my-post", draftPost)
+
+	err := drafts.Publish(path, time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC))
+	assert.OK(t, err).Fatal()
+
+	err = drafts.Unpublish(path)
+	assert.OK(t, err).Fatal()
+
+	post, err := site.LoadPost(path)
+	assert.OK(t, err).Fatal()
+	assert.True(t, "draft", post.Frontmatter.Draft)
+}
+
+func TestToday(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	assert.OK(t, err).Fatal()
+
+	// 8pm in Los Angeles is already the next day in UTC.
+	now := time.Date(2025, 3, 15, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, "today in UTC", "2025-03-15", drafts.Today(now, time.UTC).Format(drafts.DateLayout))
+	assert.Equal(t, "today in Los Angeles", "2025-03-14", drafts.Today(now, la).Format(drafts.DateLayout))
+	assert.True(t, "midnight UTC", drafts.Today(now, la).Equal(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	standalone := writePost(t, dir, "my-post", draftPost)
+	series := writePost(t, dir, "go-basics/variables", draftPost)
+
+	path, err := drafts.Resolve(dir, "my-post")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "standalone", standalone, path)
+
+	path, err = drafts.Resolve(dir, "go-basics/variables")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "series", series, path)
+
+	path, err = drafts.Resolve(dir, series)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "path", series, path)
+
+	_, err = drafts.Resolve(dir, "missing")
+	assert.Error(t, err, "no post with slug")
+}
@@ -0,0 +1,171 @@
+// Package gitlog reads file creation and modification times from the history
+// of a local git repository.
+package gitlog
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// commitMarker prefixes the date line of each commit in the log output so it
+// can't be confused with a file name.
+const commitMarker = "\x1e"
+
+// Times contains the commit times for a single file.
+type Times struct {
+	// The time of the earliest commit that touched the file.
+	Created time.Time
+
+	// The time of the most recent commit that touched the file.
+	Modified time.Time
+}
+
+// History maps file paths, relative to the directory it was loaded from, to
+// their commit times.
+type History map[string]Times
+
+// Load reads the git history for every file under dir by running git log once.
+// Paths in the returned history are relative to dir and use forward slashes.
+// Returns an error if git is not installed or dir is not inside a repository.
+func Load(dir string) (History, error) {
+	// File names are separated by NUL bytes with -z, which also stops git
+	// from quoting names containing special or non-ASCII characters.
+	out, err := run(dir, "log", "--format="+commitMarker+"%aI", "--name-only", "-z", "--relative", "--no-renames", "--", ".")
+	if err != nil {
+		return nil, fmt.Errorf("gitlog: load: %s: %w", dir, err)
+	}
+
+	history, err := parse(out)
+	if err != nil {
+		return nil, fmt.Errorf("gitlog: load: %s: %w", dir, err)
+	}
+
+	return history, nil
+}
+
+// parse reads git log output, which lists commits newest first, each as a
+// marked date followed by the names of the files it touched. Every field is
+// terminated by a NUL byte, and the first name after each date is preceded by
+// a newline.
+func parse(out []byte) (History, error) {
+	history := History{}
+
+	var current time.Time
+	afterDate := false
+	for _, field := range strings.Split(string(out), "\x00") {
+		if date, ok := strings.CutPrefix(field, commitMarker); ok {
+			t, err := time.Parse(time.RFC3339, date)
+			if err != nil {
+				return nil, err
+			}
+			current = t
+			afterDate = true
+			continue
+		}
+
+		if afterDate {
+			field = strings.TrimPrefix(field, "\n")
+			afterDate = false
+		}
+		if field == "" {
+			continue
+		}
+
+		times, ok := history[field]
+		if !ok {
+			times.Modified = current
+		}
+		times.Created = current
+		history[field] = times
+	}
+
+	return history, nil
+}
+
+// Lookup returns the commit times for the file at path, which is relative to
+// the directory the history was loaded from. The second return value is false
+// if the file has never been committed.
+func (h History) Lookup(path string) (Times, bool) {
+	times, ok := h[filepath.ToSlash(filepath.Clean(path))]
+	return times, ok
+}
+
+// fieldSeparator separates the fields of each commit in the revision log.
+const fieldSeparator = "\x1f"
+
+// Revision is a single commit that changed a file.
+type Revision struct {
+	// The full hash of the commit.
+	Hash string
+
+	// The author date of the commit.
+	Date time.Time
+
+	// The first line of the commit message.
+	Subject string
+
+	// The contents of the file as of the commit. Empty if the commit deleted
+	// the file.
+	Content string
+}
+
+// Revisions returns every commit that changed the file at path, newest first,
+// along with the contents of the file at each commit. The path is relative to
+// dir. Renames are not followed.
+func Revisions(dir, path string) ([]Revision, error) {
+	path = filepath.ToSlash(filepath.Clean(path))
+
+	out, err := run(dir, "log", "--format="+commitMarker+"%H"+fieldSeparator+"%aI"+fieldSeparator+"%s", "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("gitlog: revisions: %s: %w", path, err)
+	}
+
+	var revisions []Revision
+	for _, record := range strings.Split(string(out), commitMarker) {
+		fields := strings.SplitN(strings.TrimSpace(record), fieldSeparator, 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		date, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("gitlog: revisions: %s: %w", path, err)
+		}
+
+		// Paths starting with ./ are resolved relative to the working
+		// directory rather than the repository root. Showing a path that a
+		// commit deleted fails, which leaves the content empty.
+		content, _ := run(dir, "show", fields[0]+":./"+path) // #nosec G104 - Deleted files have no content
+
+		revisions = append(revisions, Revision{
+			Hash:    fields[0],
+			Date:    date,
+			Subject: fields[2],
+			Content: string(content),
+		})
+	}
+
+	return revisions, nil
+}
+
+// run runs git in dir and returns its output. The error includes anything git
+// printed to stderr.
+func run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...) // #nosec G204 - Arguments are fixed apart from paths within the site directory
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
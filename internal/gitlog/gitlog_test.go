@@ -0,0 +1,120 @@
+package gitlog_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/gitlog"
+)
+
+// commit writes contents to path within dir and commits it with the given
+// author date.
+func commit(t *testing.T, dir, path, contents string, date time.Time) {
+	t.Helper()
+
+	full := filepath.Join(dir, path)
+	err := os.MkdirAll(filepath.Dir(full), 0750)
+	assert.OK(t, err).Fatal()
+	err = os.WriteFile(full, []byte(contents), 0600)
+	assert.OK(t, err).Fatal()
+
+	git(t, dir, "add", path)
+	git(t, dir, "commit", "-q", "-m", "Update "+path, "--date", date.Format(time.RFC3339))
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git(t, repo, "init", "-q")
+
+	first := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+	third := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	commit(t, repo, "site/notes/a.md", "one", first)
+	commit(t, repo, "site/notes/b.md", "one", second)
+	commit(t, repo, "site/notes/a.md", "two", third)
+	commit(t, repo, "other.md", "one", third)
+	commit(t, repo, "site/notes/café \"quoted\".md", "one", second)
+
+	history, err := gitlog.Load(filepath.Join(repo, "site"))
+	assert.OK(t, err).Fatal()
+
+	a, ok := history.Lookup(filepath.Join("notes", "a.md"))
+	assert.True(t, "a found", ok)
+	assert.True(t, "a created", a.Created.Equal(first))
+	assert.True(t, "a modified", a.Modified.Equal(third))
+
+	b, ok := history.Lookup("notes/b.md")
+	assert.True(t, "b found", ok)
+	assert.True(t, "b created", b.Created.Equal(second))
+	assert.True(t, "b modified", b.Modified.Equal(second))
+
+	// Git quotes names like this one unless told not to.
+	cafe, ok := history.Lookup("notes/café \"quoted\".md")
+	assert.True(t, "non-ASCII found", ok)
+	assert.True(t, "non-ASCII created", cafe.Created.Equal(second))
+
+	_, ok = history.Lookup("other.md")
+	assert.False(t, "outside directory", ok)
+}
+
+func TestLoad_NotARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	_, err := gitlog.Load(t.TempDir())
+	assert.Error(t, err, "gitlog: load")
+}
+
+func TestRevisions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git(t, repo, "init", "-q")
+
+	first := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	commit(t, repo, "site/notes/a.md", "one\n", first)
+	commit(t, repo, "site/notes/b.md", "one\n", first)
+	commit(t, repo, "site/notes/a.md", "one\ntwo\n", second)
+
+	revisions, err := gitlog.Revisions(filepath.Join(repo, "site"), filepath.Join("notes", "a.md"))
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "revision count", 2, len(revisions))
+	assert.True(t, "newest date", revisions[0].Date.Equal(second))
+	assert.Equal(t, "newest subject", "Update site/notes/a.md", revisions[0].Subject)
+	assert.Equal(t, "newest content", "one\ntwo\n", revisions[0].Content)
+	assert.Equal(t, "oldest content", "one\n", revisions[1].Content)
+	assert.Equal(t, "hash length", 40, len(revisions[1].Hash))
+
+	revisions, err = gitlog.Revisions(filepath.Join(repo, "site"), "notes/missing.md")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "uncommitted revisions", 0, len(revisions))
+}
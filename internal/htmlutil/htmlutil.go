@@ -0,0 +1,265 @@
+// Package htmlutil provides utilities for inspecting rendered HTML content.
+package htmlutil
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Image represents an img element found in an HTML document.
+type Image struct {
+	// The value of the src attribute.
+	Src string
+
+	// The value of the alt attribute.
+	Alt string
+
+	// Whether the element has an alt attribute at all.
+	HasAlt bool
+}
+
+// Link represents a reference from an HTML element to another resource.
+type Link struct {
+	// The name of the element containing the reference e.g. "a", "img".
+	Tag string
+
+	// The name of the attribute containing the reference e.g. "href", "src".
+	Attr string
+
+	// The referenced URL exactly as written in the document.
+	URL string
+}
+
+// Links returns every href and src reference in the given HTML content in
+// document order.
+func Links(content string) []Link {
+	var links []Link
+	eachStartTag(content, func(tok html.Token) {
+		for _, attr := range tok.Attr {
+			if attr.Key == "href" || attr.Key == "src" {
+				links = append(links, Link{Tag: tok.Data, Attr: attr.Key, URL: attr.Val})
+			}
+		}
+	})
+	return links
+}
+
+// IDs returns the set of fragment identifiers defined in the given HTML
+// content. This includes id attributes on any element and name attributes on
+// anchors.
+func IDs(content string) map[string]bool {
+	ids := map[string]bool{}
+	eachStartTag(content, func(tok html.Token) {
+		for _, attr := range tok.Attr {
+			if attr.Key == "id" || (attr.Key == "name" && tok.Data == "a") {
+				ids[attr.Val] = true
+			}
+		}
+	})
+	return ids
+}
+
+// Images returns every img element in the given HTML fragment in document
+// order.
+func Images(content string) []Image {
+	var images []Image
+	eachStartTag(content, func(tok html.Token) {
+		if tok.Data != "img" {
+			return
+		}
+
+		var img Image
+		for _, attr := range tok.Attr {
+			switch attr.Key {
+			case "src":
+				img.Src = attr.Val
+			case "alt":
+				img.Alt = attr.Val
+				img.HasAlt = true
+			}
+		}
+		images = append(images, img)
+	})
+	return images
+}
+
+// Section returns the heading with the given id in an HTML fragment along with
+// the content that follows it, up to the next heading of the same or a higher
+// level. Only top-level headings are considered. The second return value is
+// false if there is no such heading.
+func Section(content, id string) (string, bool) {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", false
+	}
+
+	var sb strings.Builder
+	level := 0
+	for _, node := range nodes {
+		nodeLevel := headingLevel(node)
+		if level == 0 {
+			if nodeLevel == 0 || attr(node, "id") != id {
+				continue
+			}
+			level = nodeLevel
+		} else if nodeLevel > 0 && nodeLevel <= level {
+			break
+		}
+
+		if err := html.Render(&sb, node); err != nil {
+			return "", false
+		}
+	}
+
+	return sb.String(), level > 0
+}
+
+// PrefixIDs adds prefix to every id in content so that it can be included in
+// another page without duplicating its ids. References to those ids from
+// fragment links and label for attributes are updated to match, so that links
+// within content, such as to footnotes, still work once it's included.
+func PrefixIDs(content, prefix string) string {
+	ids := IDs(content)
+
+	var sb strings.Builder
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return sb.String()
+		}
+
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			sb.Write(z.Raw())
+			continue
+		}
+
+		tok := z.Token()
+		for i, a := range tok.Attr {
+			switch {
+			case a.Key == "id" || (a.Key == "name" && tok.Data == "a"):
+				tok.Attr[i].Val = prefix + a.Val
+			case a.Key == "href" && strings.HasPrefix(a.Val, "#") && ids[a.Val[1:]]:
+				tok.Attr[i].Val = "#" + prefix + a.Val[1:]
+			case a.Key == "for" && ids[a.Val]:
+				tok.Attr[i].Val = prefix + a.Val
+			}
+		}
+		sb.WriteString(tok.String())
+	}
+}
+
+// Text returns the text content of the given HTML content with runs of
+// whitespace collapsed to a single space. The contents of script and style
+// elements are ignored.
+func Text(content string) string {
+	var sb strings.Builder
+	skip := 0
+
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(sb.String()), " ")
+		case html.StartTagToken:
+			if name, _ := z.TagName(); isRawText(string(name)) {
+				skip++
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); isRawText(string(name)) && skip > 0 {
+				skip--
+			}
+			// End tags separate words in adjacent block elements.
+			sb.WriteByte(' ')
+		case html.TextToken:
+			if skip == 0 {
+				sb.Write(z.Text())
+			}
+		}
+	}
+}
+
+// Headings returns the text of every heading in the given HTML content in
+// document order.
+func Headings(content string) []string {
+	var headings []string
+
+	z := html.NewTokenizer(strings.NewReader(content))
+	var sb strings.Builder
+	depth := 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return headings
+		case html.StartTagToken:
+			if name, _ := z.TagName(); isHeading(string(name)) {
+				depth++
+			}
+		case html.EndTagToken:
+			if name, _ := z.TagName(); isHeading(string(name)) && depth > 0 {
+				depth--
+				if depth == 0 {
+					headings = append(headings, strings.Join(strings.Fields(sb.String()), " "))
+					sb.Reset()
+				}
+			}
+		case html.TextToken:
+			if depth > 0 {
+				sb.Write(z.Text())
+			}
+		}
+	}
+}
+
+func isHeading(name string) bool {
+	switch name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return true
+	}
+	return false
+}
+
+func isRawText(name string) bool {
+	return name == "script" || name == "style"
+}
+
+// headingLevel returns the level of a heading element, or 0 if node is not a
+// heading.
+func headingLevel(node *html.Node) int {
+	if node.Type != html.ElementNode || len(node.Data) != 2 || node.Data[0] != 'h' {
+		return 0
+	}
+	if level := int(node.Data[1] - '0'); level >= 1 && level <= 6 {
+		return level
+	}
+	return 0
+}
+
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// eachStartTag calls fn for every start or self-closing tag in content.
+func eachStartTag(content string, fn func(html.Token)) {
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return
+		}
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			fn(z.Token())
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package htmlutil_test
+
+import (
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/htmlutil"
+)
+
+func TestImages(t *testing.T) {
+	content := `<p><img src="/a.png" alt="A cat"><img src="/b.png" alt=""/><img src="/c.png"></p>`
+
+	images := htmlutil.Images(content)
+
+	assert.Equal(t, "image count", 3, len(images))
+	assert.Equal(t, "first src", "/a.png", images[0].Src)
+	assert.Equal(t, "first alt", "A cat", images[0].Alt)
+	assert.True(t, "second has alt", images[1].HasAlt)
+	assert.Equal(t, "second alt", "", images[1].Alt)
+	assert.False(t, "third has alt", images[2].HasAlt)
+}
+
+func TestImages_None(t *testing.T) {
+	images := htmlutil.Images(`<p>No images here.</p>`)
+
+	assert.Equal(t, "image count", 0, len(images))
+}
+
+func TestLinks(t *testing.T) {
+	content := `<a href="/posts/one#intro">One</a><img src="cat.png"><link rel="alternate" href="/rss.xml"><p>Text</p>`
+
+	links := htmlutil.Links(content)
+
+	assert.Equal(t, "link count", 3, len(links))
+	assert.Equal(t, "first url", "/posts/one#intro", links[0].URL)
+	assert.Equal(t, "second tag", "img", links[1].Tag)
+	assert.Equal(t, "second attr", "src", links[1].Attr)
+	assert.Equal(t, "third url", "/rss.xml", links[2].URL)
+}
+
+func TestIDs(t *testing.T) {
+	content := `<h2 id="intro">Intro</h2><a name="legacy"></a><div name="ignored"></div>`
+
+	ids := htmlutil.IDs(content)
+
+	assert.Equal(t, "id count", 2, len(ids))
+	assert.True(t, "has intro", ids["intro"])
+	assert.True(t, "has legacy", ids["legacy"])
+}
+
+func TestSection(t *testing.T) {
+	content := `<h1 id="intro">Intro</h1><p>Hello</p><h2 id="usage">Usage</h2><p>Run it.</p><h3 id="flags">Flags</h3><p>None.</p><h2 id="faq">FAQ</h2><p>Ask.</p>`
+
+	section, ok := htmlutil.Section(content, "usage")
+	assert.True(t, "found", ok)
+	assert.Equal(t, "section", `<h2 id="usage">Usage</h2><p>Run it.</p><h3 id="flags">Flags</h3><p>None.</p>`, section)
+
+	section, ok = htmlutil.Section(content, "faq")
+	assert.True(t, "last section found", ok)
+	assert.Equal(t, "last section", `<h2 id="faq">FAQ</h2><p>Ask.</p>`, section)
+
+	_, ok = htmlutil.Section(content, "missing")
+	assert.False(t, "missing found", ok)
+}
+
+func TestPrefixIDs(t *testing.T) {
+	content := `<h2 id="usage" class="title">Usage &amp; more</h2><p>Text<sup id="fnref:1"><a href="#fn:1">1</a></sup>` +
+		`<label for="sn:1">1</label><input id="sn:1"><a href="#other">Other</a><a href="/notes/vim#usage">Vim</a><br/></p>` +
+		`<li id="fn:1"><a href="#fnref:1">↩︎</a></li>`
+
+	want := `<h2 id="x-usage" class="title">Usage &amp; more</h2><p>Text<sup id="x-fnref:1"><a href="#x-fn:1">1</a></sup>` +
+		`<label for="x-sn:1">1</label><input id="x-sn:1"><a href="#other">Other</a><a href="/notes/vim#usage">Vim</a><br/></p>` +
+		`<li id="x-fn:1"><a href="#x-fnref:1">↩︎</a></li>`
+	assert.Equal(t, "prefixed", want, htmlutil.PrefixIDs(content, "x-"))
+}
+
+func TestText(t *testing.T) {
+	content := "<h1>Title</h1><p>Some <em>emphasized</em>\n  text &amp; more.</p><script>var x = 1;</script><p>End</p>"
+
+	assert.Equal(t, "text", "Title Some emphasized text & more. End", htmlutil.Text(content))
+}
+
+func TestHeadings(t *testing.T) {
+	content := `<h1 id="intro">Intro</h1><p>Hello</p><h2>Using <code>go test</code></h2><p>Run it.</p>`
+
+	assert.SliceEqual(t, "headings", []string{"Intro", "Using go test"}, htmlutil.Headings(content))
+}
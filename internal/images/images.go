@@ -0,0 +1,483 @@
+// Package images generates responsive variants of the local raster images
+// referenced by site content.
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	"golang.org/x/net/html"
+)
+
+// Dir is the directory in a site, and the URL path, from which images are
+// served.
+const Dir = "images"
+
+// Sizes is the sizes attribute given to responsive images. It matches the
+// width of the content column in the site layout.
+const Sizes = "(min-width: 640px) 75vw, 100vw"
+
+// Media types of image variants.
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	WebP = "image/webp"
+)
+
+// Widths are the widths, in pixels, of the resized variants generated for
+// each image. Images are never enlarged, so only the widths smaller than an
+// image are generated.
+var Widths = []int{480, 960, 1440}
+
+// jpegQuality is the quality used when encoding resized JPEG variants.
+const jpegQuality = 85
+
+// cacheVersion is included in cache keys so that changes to how variants are
+// generated invalidate previously cached variants.
+const cacheVersion = 1
+
+// Variant is a single encoding of an image at a specific width.
+type Variant struct {
+	// The URL of the variant e.g. "/images/photo-480w.webp".
+	URL string
+
+	// The width of the variant in pixels.
+	Width int
+
+	// The media type of the variant e.g. "image/webp".
+	Type string
+
+	// The path of the variant on disk. This is the source file for the
+	// original image and a file in the cache for generated variants.
+	Path string
+}
+
+// Image is a local raster image along with its generated variants.
+type Image struct {
+	// The URL of the original image e.g. "/images/photo.jpg".
+	Src string
+
+	// The dimensions of the original image in pixels.
+	Width, Height int
+
+	// The variants of the image in ascending order of width, with the
+	// variants in the original format before any WebP variants. The original
+	// image is the widest variant in its format.
+	Variants []Variant
+}
+
+// SrcSet returns a srcset attribute value listing the variants of the image
+// with the given media type.
+func (img *Image) SrcSet(typ string) string {
+	var candidates []string
+	for _, v := range img.Variants {
+		if v.Type == typ {
+			candidates = append(candidates, v.URL+" "+strconv.Itoa(v.Width)+"w")
+		}
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// Type returns the media type of the original image.
+func (img *Image) Type() string {
+	return mediaType(img.Src)
+}
+
+// Find returns the variant of one of imgs with the given URL. The second
+// return value is false if there is no such variant.
+func Find(imgs []*Image, url string) (Variant, bool) {
+	for _, img := range imgs {
+		for _, v := range img.Variants {
+			if v.URL == url {
+				return v, true
+			}
+		}
+	}
+	return Variant{}, false
+}
+
+// Processor generates variants of the images in a site directory, caching them
+// so that unchanged images are only processed once.
+type Processor struct {
+	// The root directory of the site.
+	SrcDir string
+
+	// The directory in which generated variants are cached.
+	CacheDir string
+
+	// The processed images, by URL.
+	images map[string]*Image
+
+	// The processed images in the order they were first processed.
+	order []*Image
+}
+
+// NewProcessor creates a new processor for the images in srcDir that caches
+// variants in cacheDir.
+func NewProcessor(srcDir, cacheDir string) *Processor {
+	return &Processor{
+		SrcDir:   srcDir,
+		CacheDir: cacheDir,
+		images:   map[string]*Image{},
+	}
+}
+
+// Images returns every image processed so far in the order they were first
+// processed.
+func (p *Processor) Images() []*Image {
+	return p.order
+}
+
+// Rewrite replaces every img element in content that references a local JPEG
+// or PNG image with a picture element offering WebP and resized variants of
+// the image, or just a srcset of resized variants if the image has no WebP
+// variants. The img element is given the dimensions of the image and is
+// lazily loaded. Other images, and images already in a picture element, are
+// left as-is.
+func (p *Processor) Rewrite(content string) (string, error) {
+	if !strings.Contains(content, "<img") {
+		return content, nil
+	}
+
+	var sb strings.Builder
+	pictures := 0
+
+	z := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return "", fmt.Errorf("images: rewrite: %w", err)
+			}
+			return sb.String(), nil
+		}
+
+		raw := string(z.Raw())
+		if tt != html.StartTagToken && tt != html.EndTagToken && tt != html.SelfClosingTagToken {
+			sb.WriteString(raw)
+			continue
+		}
+
+		tok := z.Token()
+		switch {
+		case tt == html.StartTagToken && tok.Data == "picture":
+			pictures++
+		case tt == html.EndTagToken && tok.Data == "picture" && pictures > 0:
+			pictures--
+		}
+
+		src, ok := localSource(attrValue(tok, "src"))
+		if tt == html.EndTagToken || tok.Data != "img" || pictures > 0 || !ok {
+			sb.WriteString(raw)
+			continue
+		}
+
+		img, err := p.Process(src)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(picture(tok, img))
+	}
+}
+
+// Process generates the variants of the image at src, a URL such as
+// "/images/photo.jpg", or loads them from the cache if the image hasn't
+// changed since they were generated.
+func (p *Processor) Process(src string) (*Image, error) {
+	if img, ok := p.images[src]; ok {
+		return img, nil
+	}
+
+	srcPath := filepath.Join(p.SrcDir, filepath.FromSlash(strings.TrimPrefix(src, "/")))
+	data, err := os.ReadFile(srcPath) // #nosec G304 - Images are read from the user-specified site directory
+	if err != nil {
+		return nil, fmt.Errorf("images: process: %s: %w", src, err)
+	}
+
+	cacheDir := filepath.Join(p.CacheDir, cacheKey(data))
+	meta, err := readMetadata(cacheDir)
+	if err != nil {
+		log.Printf("Processing image %s...", src)
+		if meta, err = generate(cacheDir, data, mediaType(src)); err != nil {
+			return nil, fmt.Errorf("images: process: %s: %w", src, err)
+		}
+	}
+
+	img := &Image{Src: src, Width: meta.Width, Height: meta.Height}
+	base := strings.TrimSuffix(src, path.Ext(src))
+	ext := path.Ext(src)
+	widths := variantWidths(meta.Width)
+	for _, w := range widths {
+		img.Variants = append(img.Variants, Variant{
+			URL:   fmt.Sprintf("%s-%dw%s", base, w, ext),
+			Width: w,
+			Type:  img.Type(),
+			Path:  filepath.Join(cacheDir, strconv.Itoa(w)+extension(img.Type())),
+		})
+	}
+	img.Variants = append(img.Variants, Variant{URL: src, Width: meta.Width, Type: img.Type(), Path: srcPath})
+	if meta.WebP {
+		for _, w := range append(widths, meta.Width) {
+			img.Variants = append(img.Variants, Variant{
+				URL:   fmt.Sprintf("%s-%dw.webp", base, w),
+				Width: w,
+				Type:  WebP,
+				Path:  filepath.Join(cacheDir, strconv.Itoa(w)+".webp"),
+			})
+		}
+	}
+
+	p.images[src] = img
+	p.order = append(p.order, img)
+
+	return img, nil
+}
+
+// metadata describes a cached image. It is written once all of the variants of
+// an image have been generated and closed, so an image without it is generated
+// again.
+type metadata struct {
+	// The dimensions of the original image in pixels.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// Whether WebP variants were generated. WebP variants are lossless, so
+	// they are dropped if they would be larger than the original format.
+	WebP bool `json:"webp"`
+}
+
+func readMetadata(dir string) (metadata, error) {
+	var meta metadata
+
+	data, err := os.ReadFile(filepath.Join(dir, "image.json")) // #nosec G304 - Reading from the user-specified cache directory is intentional
+	if err != nil {
+		return meta, err
+	}
+
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return meta, err
+	}
+
+	return meta, nil
+}
+
+// generate decodes the image in data and writes its variants to dir.
+func generate(dir string, data []byte, typ string) (metadata, error) {
+	var meta metadata
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return meta, fmt.Errorf("decode: %w", err)
+	}
+	meta.Width, meta.Height = src.Bounds().Dx(), src.Bounds().Dy()
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return meta, err
+	}
+
+	originalSize := int64(len(data))
+	webpSize := int64(0)
+	for _, width := range append(variantWidths(meta.Width), meta.Width) {
+		resized := src
+		if width != meta.Width {
+			resized = resize(src, width, max(meta.Height*width/meta.Width, 1))
+
+			n, err := writeFile(filepath.Join(dir, strconv.Itoa(width)+extension(typ)), func(w io.Writer) error {
+				if typ == PNG {
+					return png.Encode(w, resized)
+				}
+				return jpeg.Encode(w, resized, &jpeg.Options{Quality: jpegQuality})
+			})
+			if err != nil {
+				return meta, err
+			}
+			originalSize += n
+		}
+
+		n, err := writeFile(filepath.Join(dir, strconv.Itoa(width)+".webp"), func(w io.Writer) error {
+			return nativewebp.Encode(w, resized, nil)
+		})
+		if err != nil {
+			return meta, err
+		}
+		webpSize += n
+	}
+	meta.WebP = webpSize < originalSize
+	if !meta.WebP {
+		for _, width := range append(variantWidths(meta.Width), meta.Width) {
+			if err := os.Remove(filepath.Join(dir, strconv.Itoa(width)+".webp")); err != nil {
+				return meta, err
+			}
+		}
+	}
+
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		return meta, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "image.json"), encoded, 0600); err != nil {
+		return meta, err
+	}
+
+	return meta, nil
+}
+
+// resize scales src to the given dimensions.
+func resize(src image.Image, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// writeFile creates the file at path with the output of fn and returns the size
+// of the file. The file is closed before returning so that an error flushing it
+// is reported, and removed if it couldn't be written completely.
+func writeFile(path string, fn func(io.Writer) error) (int64, error) {
+	f, err := os.Create(path) // #nosec G304 - Writing to the user-specified cache directory is intentional
+	if err != nil {
+		return 0, err
+	}
+
+	err = fn(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path) // #nosec G104 - The write error takes precedence
+		return 0, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+// variantWidths returns the widths of the resized variants of an image with
+// the given width.
+func variantWidths(width int) []int {
+	var widths []int
+	for _, w := range Widths {
+		if w < width {
+			widths = append(widths, w)
+		}
+	}
+	return widths
+}
+
+// cacheKey returns the name of the cache directory for an image with the
+// given contents.
+func cacheKey(data []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d %v\n", cacheVersion, Widths)
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// localSource returns the cleaned path of src if it references a JPEG or PNG
+// image in the images directory of the site. The second return value is false
+// otherwise.
+func localSource(src string) (string, bool) {
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.RawQuery != "" || u.Fragment != "" {
+		return "", false
+	}
+
+	p := path.Clean(u.Path)
+	if !strings.HasPrefix(p, "/"+Dir+"/") || mediaType(p) == "" {
+		return "", false
+	}
+
+	return p, true
+}
+
+// mediaType returns the media type of an image with the given path, or an
+// empty string if the image is not a JPEG or PNG.
+func mediaType(p string) string {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".jpg", ".jpeg":
+		return JPEG
+	case ".png":
+		return PNG
+	}
+	return ""
+}
+
+// extension returns the file extension used for resized variants with the
+// given media type.
+func extension(typ string) string {
+	if typ == PNG {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// picture returns a picture element for img, using the attributes of the
+// original img element tok. If there are no WebP variants, only the img
+// element is returned.
+func picture(tok html.Token, img *Image) string {
+	set := func(key, val string) {
+		for i, a := range tok.Attr {
+			if a.Key == key {
+				tok.Attr[i].Val = val
+				return
+			}
+		}
+		tok.Attr = append(tok.Attr, html.Attribute{Key: key, Val: val})
+	}
+	setDefault := func(key, val string) {
+		if attrValue(tok, key) == "" {
+			set(key, val)
+		}
+	}
+
+	tok.Type = html.StartTagToken
+	set("src", img.Src)
+	set("srcset", img.SrcSet(img.Type()))
+	set("sizes", Sizes)
+	setDefault("width", strconv.Itoa(img.Width))
+	setDefault("height", strconv.Itoa(img.Height))
+	setDefault("loading", "lazy")
+	setDefault("decoding", "async")
+
+	srcset := img.SrcSet(WebP)
+	if srcset == "" {
+		return tok.String()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<picture>")
+	sb.WriteString(`<source type="` + WebP + `" srcset="` + html.EscapeString(srcset) + `" sizes="` + Sizes + `">`)
+	sb.WriteString(tok.String())
+	sb.WriteString("</picture>")
+	return sb.String()
+}
+
+func attrValue(tok html.Token, key string) string {
+	for _, a := range tok.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package images_test
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/images"
+)
+
+// writePNG writes a PNG image with the given dimensions to path.
+func writePNG(t *testing.T, path string, width, height int) {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0750)
+	assert.OK(t, err).Fatal()
+
+	f, err := os.Create(path)
+	assert.OK(t, err).Fatal()
+	defer f.Close()
+
+	err = png.Encode(f, img)
+	assert.OK(t, err).Fatal()
+}
+
+func TestProcessor_Rewrite(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "images", "photo.png"), 1000, 500)
+
+	p := images.NewProcessor(dir, filepath.Join(dir, ".stele", "images"))
+	got, err := p.Rewrite(`<p><img src="/images/photo.png" alt="A photo"></p>`)
+	assert.OK(t, err).Fatal()
+
+	want := `<p><picture><source type="image/webp" srcset="/images/photo-480w.webp 480w, /images/photo-960w.webp 960w, /images/photo-1000w.webp 1000w" sizes="(min-width: 640px) 75vw, 100vw">` +
+		`<img src="/images/photo.png" alt="A photo" srcset="/images/photo-480w.png 480w, /images/photo-960w.png 960w, /images/photo.png 1000w" sizes="(min-width: 640px) 75vw, 100vw" width="1000" height="500" loading="lazy" decoding="async">` +
+		`</picture></p>`
+	assert.Equal(t, "content", want, got)
+
+	assert.Equal(t, "image count", 1, len(p.Images()))
+	img := p.Images()[0]
+	assert.Equal(t, "width", 1000, img.Width)
+	assert.Equal(t, "height", 500, img.Height)
+	for _, v := range img.Variants {
+		_, err := os.Stat(v.Path)
+		assert.OK(t, err)
+	}
+
+	v, ok := images.Find(p.Images(), "/images/photo-480w.png")
+	assert.True(t, "found variant", ok)
+	assert.Equal(t, "variant width", 480, v.Width)
+	assert.Equal(t, "variant type", images.PNG, v.Type)
+}
+
+func TestProcessor_Rewrite_Unprocessed(t *testing.T) {
+	dir := t.TempDir()
+	p := images.NewProcessor(dir, filepath.Join(dir, ".stele", "images"))
+
+	tests := map[string]string{
+		"remote image":     `<img src="https://example.com/photo.png" alt="Remote">`,
+		"vector image":     `<img src="/images/diagram.svg" alt="Diagram">`,
+		"outside images":   `<img src="/photo.png" alt="Elsewhere">`,
+		"existing picture": `<picture><source srcset="/images/a.webp"><img src="/images/a.png" alt="A"></picture>`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := p.Rewrite(content)
+			assert.OK(t, err).Fatal()
+			assert.Equal(t, "content", content, got)
+		})
+	}
+}
+
+func TestProcessor_Rewrite_Missing(t *testing.T) {
+	dir := t.TempDir()
+	p := images.NewProcessor(dir, filepath.Join(dir, ".stele", "images"))
+
+	_, err := p.Rewrite(`<img src="/images/missing.png" alt="Missing">`)
+	assert.Error(t, err, "images: process: /images/missing.png")
+}
+
+func TestProcessor_Process_Cache(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, ".stele", "images")
+	path := filepath.Join(dir, "images", "photo.png")
+	writePNG(t, path, 600, 300)
+
+	img, err := images.NewProcessor(dir, cacheDir).Process("/images/photo.png")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "width", 600, img.Width)
+
+	// The cached metadata is used as-is, so changing it shows that the image
+	// wasn't processed again.
+	entries, err := os.ReadDir(cacheDir)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "cache entries", 1, len(entries))
+	metaPath := filepath.Join(cacheDir, entries[0].Name(), "image.json")
+	err = os.WriteFile(metaPath, []byte(`{"width":601,"height":300,"webp":false}`), 0600)
+	assert.OK(t, err).Fatal()
+
+	p := images.NewProcessor(dir, cacheDir)
+	got, err := p.Rewrite(`<img src="/images/photo.png" alt="A photo">`)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "cached width", 601, p.Images()[0].Width)
+	assert.False(t, "picture without webp", strings.Contains(got, "<picture>"))
+	assert.True(t, "srcset", strings.Contains(got, `srcset="/images/photo-480w.png 480w, /images/photo.png 601w"`))
+
+	// Changing the image invalidates the cache.
+	writePNG(t, path, 500, 250)
+	img, err = images.NewProcessor(dir, cacheDir).Process("/images/photo.png")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "updated width", 500, img.Width)
+	assert.Equal(t, "updated height", 250, img.Height)
+}
+
+func TestProcessor_Process_FailedVariant(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, ".stele", "images")
+	writePNG(t, filepath.Join(dir, "images", "photo.png"), 600, 300)
+
+	_, err := images.NewProcessor(dir, cacheDir).Process("/images/photo.png")
+	assert.OK(t, err).Fatal()
+
+	entries, err := os.ReadDir(cacheDir)
+	assert.OK(t, err).Fatal()
+	imageDir := filepath.Join(cacheDir, entries[0].Name())
+	metaPath := filepath.Join(imageDir, "image.json")
+	variantPath := filepath.Join(imageDir, "480.png")
+
+	// A directory in place of a variant stops it from being written.
+	assert.OK(t, os.Remove(metaPath)).Fatal()
+	assert.OK(t, os.Remove(variantPath)).Fatal()
+	assert.OK(t, os.MkdirAll(filepath.Join(variantPath, "blocked"), 0750)).Fatal()
+
+	_, err = images.NewProcessor(dir, cacheDir).Process("/images/photo.png")
+	assert.Error(t, err, "480.png")
+	_, err = os.Stat(metaPath)
+	assert.True(t, "metadata not written", os.IsNotExist(err))
+
+	// The image is generated again once the variant can be written.
+	assert.OK(t, os.RemoveAll(variantPath)).Fatal()
+	img, err := images.NewProcessor(dir, cacheDir).Process("/images/photo.png")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "width", 600, img.Width)
+	_, err = os.Stat(variantPath)
+	assert.OK(t, err)
+}
@@ -0,0 +1,281 @@
+package markdown
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/haleyrc/stele/internal/template/components/icons"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Callouts is a goldmark extension that renders callouts, such as notes and
+// warnings, as asides. Callouts can be written as GitHub-style alerts:
+//
+//	> [!WARNING]
+//	> This deletes the database.
+//
+// or as containers fenced with three or more colons, which may give the
+// callout a title of its own:
+//
+//	::: tip Faster builds
+//	Images are cached between builds.
+//	:::
+//
+// Containers can be nested by fencing the outer container with more colons
+// than the inner one. The kinds of callout are note, tip, important, warning,
+// and caution. Blockquotes and containers of any other kind are left as-is.
+type Callouts struct{}
+
+// Extend adds the callout parser, transformer, and renderer to m.
+func (e *Callouts) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(calloutParser{}, 700),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(alertTransformer{}, 500),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(calloutRenderer{}, 500),
+	))
+}
+
+var kindCallout = ast.NewNodeKind("Callout")
+
+// calloutKind describes a kind of callout.
+type calloutKind struct {
+	// The title of callouts of this kind that don't have a title of their own.
+	title string
+
+	// The icon shown before the title.
+	icon func(size int) templ.Component
+}
+
+var calloutKinds = map[string]calloutKind{
+	"note":      {title: "Note", icon: icons.InformationCircle},
+	"tip":       {title: "Tip", icon: icons.LightBulb},
+	"important": {title: "Important", icon: icons.ExclamationCircle},
+	"warning":   {title: "Warning", icon: icons.ExclamationTriangle},
+	"caution":   {title: "Caution", icon: icons.ShieldExclamation},
+}
+
+var (
+	// alertPattern matches the first line of a GitHub-style alert.
+	alertPattern = regexp.MustCompile(`^\[!(\w+)\]\s*$`)
+
+	// containerPattern matches the opening fence of a container.
+	containerPattern = regexp.MustCompile(`^(:{3,})\s*(\w+)\s*(.*?)\s*$`)
+)
+
+// calloutNode is a block AST node for a callout. Its children are the contents
+// of the callout.
+type calloutNode struct {
+	ast.BaseBlock
+
+	// The kind of callout e.g. "note".
+	kind string
+
+	// The title of the callout. Empty to use the title of the kind.
+	title string
+
+	// The number of colons in the fence of a container, or 0 for an alert.
+	fence int
+
+	// The offset of the opening fence of a container in the source.
+	offset int
+
+	// Whether the closing fence of a container has been found.
+	closed bool
+}
+
+func (n *calloutNode) Kind() ast.NodeKind {
+	return kindCallout
+}
+
+func (n *calloutNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Kind":  n.kind,
+		"Title": n.title,
+	}, nil)
+}
+
+// calloutParser parses containers.
+type calloutParser struct{}
+
+func (calloutParser) Trigger() []byte {
+	return []byte{':'}
+}
+
+func (calloutParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	m := containerPattern.FindSubmatch(bytes.TrimRight(line[pos:], "\r\n"))
+	if m == nil {
+		return nil, parser.NoChildren
+	}
+	kind := strings.ToLower(string(m[2]))
+	if _, ok := calloutKinds[kind]; !ok {
+		return nil, parser.NoChildren
+	}
+
+	reader.AdvanceToEOL()
+	return &calloutNode{
+		kind:   kind,
+		title:  string(m[3]),
+		fence:  len(m[1]),
+		offset: segment.Start + pos,
+	}, parser.HasChildren
+}
+
+func (calloutParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, _ := reader.PeekLine()
+	n := node.(*calloutNode)
+
+	// A fence inside a code block in the container is part of the code.
+	if inCodeBlock(n, pc) {
+		return parser.Continue | parser.HasChildren
+	}
+
+	if fence := bytes.TrimSpace(line); len(fence) == n.fence && bytes.Count(fence, []byte(":")) == n.fence {
+		n.closed = true
+		reader.AdvanceToEOL()
+		return parser.Close
+	}
+
+	return parser.Continue | parser.HasChildren
+}
+
+// inCodeBlock returns true if a fenced code block inside node is still open.
+func inCodeBlock(node ast.Node, pc parser.Context) bool {
+	for _, block := range pc.OpenedBlocks() {
+		if block.Node.Kind() != ast.KindFencedCodeBlock {
+			continue
+		}
+		for parent := block.Node.Parent(); parent != nil; parent = parent.Parent() {
+			if parent == node {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (calloutParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (calloutParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (calloutParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// alertTransformer replaces blockquotes that start with an alert marker such
+// as [!NOTE] with callouts.
+type alertTransformer struct{}
+
+func (alertTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var alerts []*ast.Blockquote
+	var kinds []string
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || node.Kind() != ast.KindBlockquote {
+			return ast.WalkContinue, nil
+		}
+
+		paragraph := node.FirstChild()
+		if paragraph == nil || paragraph.Kind() != ast.KindParagraph || paragraph.Lines().Len() == 0 {
+			return ast.WalkContinue, nil
+		}
+
+		first := paragraph.Lines().At(0)
+		m := alertPattern.FindSubmatch(first.Value(source))
+		if m == nil {
+			return ast.WalkContinue, nil
+		}
+		kind := strings.ToLower(string(m[1]))
+		if _, ok := calloutKinds[kind]; !ok {
+			return ast.WalkContinue, nil
+		}
+
+		alerts = append(alerts, node.(*ast.Blockquote))
+		kinds = append(kinds, kind)
+		return ast.WalkContinue, nil
+	})
+
+	for i, blockquote := range alerts {
+		// Drop the marker, which makes up the first line of the paragraph.
+		paragraph := blockquote.FirstChild()
+		stop := paragraph.Lines().At(0).Stop
+		for child := paragraph.FirstChild(); child != nil; {
+			t, ok := child.(*ast.Text)
+			if !ok || t.Segment.Start >= stop {
+				break
+			}
+			next := child.NextSibling()
+			paragraph.RemoveChild(paragraph, child)
+			child = next
+		}
+		if !paragraph.HasChildren() {
+			blockquote.RemoveChild(blockquote, paragraph)
+		}
+
+		callout := &calloutNode{kind: kinds[i]}
+		for child := blockquote.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			callout.AppendChild(callout, child)
+			child = next
+		}
+		blockquote.Parent().ReplaceChild(blockquote.Parent(), blockquote, callout)
+	}
+}
+
+type calloutRenderer struct{}
+
+func (calloutRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindCallout, renderCallout)
+}
+
+func renderCallout(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*calloutNode)
+
+	if n.fence > 0 && !n.closed {
+		return ast.WalkStop, fmt.Errorf("line %d: callout: unclosed %s", lineAt(source, n.offset), strings.Repeat(":", n.fence))
+	}
+
+	if !entering {
+		_, _ = w.WriteString("</aside>\n")
+		return ast.WalkContinue, nil
+	}
+
+	kind := calloutKinds[n.kind]
+	title := n.title
+	if title == "" {
+		title = kind.title
+	}
+
+	_, _ = w.WriteString(`<aside class="callout callout-` + n.kind + `" role="note">` + "\n")
+	_, _ = w.WriteString(`<p class="callout-title">`)
+	if err := kind.icon(4).Render(context.Background(), w); err != nil {
+		return ast.WalkStop, err
+	}
+	_, _ = w.Write(util.EscapeHTML([]byte(title)))
+	_, _ = w.WriteString("</p>\n")
+
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,85 @@
+package markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/markdown"
+)
+
+func TestParse_Callouts(t *testing.T) {
+	// The icons are long, so they're replaced with a placeholder to keep the
+	// expected output readable.
+	svg := regexp.MustCompile(`(?s)<svg.*?</svg>`)
+
+	testCases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "alert",
+			source: "> [!WARNING]\n> This deletes the *database*.",
+			want:   "<aside class=\"callout callout-warning\" role=\"note\">\n<p class=\"callout-title\"><svg>Warning</p>\n<p>This deletes the <em>database</em>.</p>\n</aside>",
+		},
+		{
+			name:   "unknown alert",
+			source: "> [!DANGER]\n> Quoted.",
+			want:   "<blockquote>\n<p>[!DANGER]\nQuoted.</p>\n</blockquote>",
+		},
+		{
+			name:   "container",
+			source: "::: tip\nImages are cached.\n:::\n\nAfter.",
+			want:   "<aside class=\"callout callout-tip\" role=\"note\">\n<p class=\"callout-title\"><svg>Tip</p>\n<p>Images are cached.</p>\n</aside>\n<p>After.</p>",
+		},
+		{
+			name:   "container title",
+			source: "::: note Faster <builds>\nText.\n:::",
+			want:   "<aside class=\"callout callout-note\" role=\"note\">\n<p class=\"callout-title\"><svg>Faster &lt;builds&gt;</p>\n<p>Text.</p>\n</aside>",
+		},
+		{
+			name:   "nested containers",
+			source: "::::: note\nOuter.\n\n::: caution\nInner.\n:::\n:::::",
+			want: "<aside class=\"callout callout-note\" role=\"note\">\n<p class=\"callout-title\"><svg>Note</p>\n<p>Outer.</p>\n" +
+				"<aside class=\"callout callout-caution\" role=\"note\">\n<p class=\"callout-title\"><svg>Caution</p>\n<p>Inner.</p>\n</aside>\n</aside>",
+		},
+		{
+			name:   "fence in code block",
+			source: "::: note\n```\n:::\n```\n:::\n\n## After",
+			want:   "<aside class=\"callout callout-note\" role=\"note\">\n<p class=\"callout-title\"><svg>Note</p>\n<pre><code>:::\n</code></pre>\n</aside>\n<h2 id=\"after\">After</h2>",
+		},
+		{
+			name:   "unknown container",
+			source: "::: spoiler\nText.\n:::",
+			want:   "<p>::: spoiler\nText.\n:::</p>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			var sb strings.Builder
+			err = markdown.Parse(path, &sb)
+			assert.OK(t, err).Fatal()
+
+			got := svg.ReplaceAllString(strings.TrimSpace(sb.String()), "<svg>")
+			assert.Equal(t, "html", tc.want, got)
+		})
+	}
+}
+
+func TestParse_Callouts_Unclosed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "post.md")
+	err := os.WriteFile(path, []byte("Intro.\n\n:::: warning\nText.\n:::\n\nMore."), 0600)
+	assert.OK(t, err).Fatal()
+
+	err = markdown.Parse(path, &strings.Builder{})
+	assert.Error(t, err, "line 3: callout: unclosed ::::")
+}
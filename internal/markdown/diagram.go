@@ -0,0 +1,118 @@
+package markdown
+
+import (
+	"strconv"
+
+	"github.com/haleyrc/stele/internal/diagram"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Diagrams is a goldmark extension that renders diagram code blocks, that is
+// fenced code blocks tagged with diagram.Language, as inline SVG.
+type Diagrams struct {
+	// Renders the diagrams, caching them and deciding whether errors are
+	// shown in place of the diagram. If nil, diagrams are rendered as code
+	// blocks.
+	Renderer *diagram.Renderer
+}
+
+// Extend adds the diagram transformer and renderer to m.
+func (e *Diagrams) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithASTTransformers(
+			util.Prioritized(diagramTransformer{}, 500),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&diagramRenderer{ext: e}, 500),
+	))
+}
+
+var kindDiagram = ast.NewNodeKind("Diagram")
+
+// diagramNode is a block AST node for a diagram code block. Its lines are the
+// source of the diagram.
+type diagramNode struct {
+	ast.BaseBlock
+
+	// The line of the opening fence in the source.
+	line int
+}
+
+func (n *diagramNode) Kind() ast.NodeKind {
+	return kindDiagram
+}
+
+func (n *diagramNode) IsRaw() bool {
+	return true
+}
+
+func (n *diagramNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Line": strconv.Itoa(n.line),
+	}, nil)
+}
+
+// diagramTransformer replaces diagram code blocks with diagram nodes.
+type diagramTransformer struct{}
+
+func (diagramTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	var blocks []*ast.FencedCodeBlock
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if block, ok := node.(*ast.FencedCodeBlock); ok && entering {
+			if string(block.Language(source)) == diagram.Language {
+				blocks = append(blocks, block)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, block := range blocks {
+		n := &diagramNode{line: lineAt(source, block.Info.Segment.Start)}
+		n.SetLines(block.Lines())
+		block.Parent().ReplaceChild(block.Parent(), block, n)
+	}
+}
+
+type diagramRenderer struct {
+	ext *Diagrams
+}
+
+func (r *diagramRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindDiagram, r.renderDiagram)
+}
+
+func (r *diagramRenderer) renderDiagram(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*diagramNode)
+
+	var src []byte
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		src = append(src, line.Value(source)...)
+	}
+
+	if r.ext.Renderer == nil {
+		_, _ = w.WriteString(`<pre><code class="language-` + diagram.Language + `">`)
+		_, _ = w.Write(util.EscapeHTML(src))
+		_, _ = w.WriteString("</code></pre>\n")
+		return ast.WalkSkipChildren, nil
+	}
+
+	html, err := r.ext.Renderer.HTML(string(src), n.line)
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	_, _ = w.WriteString(html + "\n")
+
+	return ast.WalkSkipChildren, nil
+}
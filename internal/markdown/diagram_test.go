@@ -0,0 +1,75 @@
+package markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/diagram"
+	"github.com/haleyrc/stele/internal/markdown"
+)
+
+func TestParse_Diagrams(t *testing.T) {
+	source := "Intro.\n\n```goat\n+--+\n|<>|\n+--+\n```\n\n```go\nx := 1\n```"
+	path := filepath.Join(t.TempDir(), "post.md")
+	err := os.WriteFile(path, []byte(source), 0600)
+	assert.OK(t, err).Fatal()
+
+	var sb strings.Builder
+	err = markdown.Parse(path, &sb)
+	assert.OK(t, err).Fatal()
+
+	want := "<p>Intro.</p>\n<pre><code class=\"language-goat\">+--+\n|&lt;&gt;|\n+--+\n</code></pre>\n" +
+		"<pre><code class=\"language-go\">x := 1\n</code></pre>"
+	assert.Equal(t, "html", want, strings.TrimSpace(sb.String()))
+}
+
+func TestDocument_RenderWith_Diagrams(t *testing.T) {
+	source := "Intro.\n\n```goat\n+--+\n|<>|\n+--+\n```\n\n```go\nx := 1\n```"
+	path := filepath.Join(t.TempDir(), "post.md")
+	err := os.WriteFile(path, []byte(source), 0600)
+	assert.OK(t, err).Fatal()
+
+	doc, err := markdown.Load(path)
+	assert.OK(t, err).Fatal()
+
+	r := diagram.NewRenderer(t.TempDir())
+	var sb strings.Builder
+	err = doc.RenderWith(&sb, markdown.RenderOptions{
+		Diagrams: markdown.Diagrams{Renderer: r},
+	})
+	assert.OK(t, err).Fatal()
+
+	got := sb.String()
+	assert.True(t, "diagram", strings.HasPrefix(got, "<p>Intro.</p>\n<div class=\"diagram\"><svg "))
+	assert.True(t, "other code", strings.HasSuffix(got, "</svg></div>\n<pre><code class=\"language-go\">x := 1\n</code></pre>\n"))
+	assert.Equal(t, "count", 1, r.Count())
+}
+
+func TestDocument_RenderWith_Diagrams_Error(t *testing.T) {
+	source := "Intro.\n\n```goat\n+--+\n|\t|\n```\n"
+	path := filepath.Join(t.TempDir(), "post.md")
+	err := os.WriteFile(path, []byte(source), 0600)
+	assert.OK(t, err).Fatal()
+
+	doc, err := markdown.Load(path)
+	assert.OK(t, err).Fatal()
+
+	r := diagram.NewRenderer(t.TempDir())
+	err = doc.RenderWith(&strings.Builder{}, markdown.RenderOptions{
+		Diagrams: markdown.Diagrams{Renderer: r},
+	})
+	assert.Error(t, err, path+": line 3: diagram: render: line 2, column 2: tabs aren't allowed")
+
+	r.ShowErrors = true
+	var sb strings.Builder
+	err = doc.RenderWith(&sb, markdown.RenderOptions{
+		Diagrams: markdown.Diagrams{Renderer: r},
+	})
+	assert.OK(t, err).Fatal()
+	assert.SliceEqual(t, "errors", []string{
+		"line 3: diagram: render: line 2, column 2: tabs aren't allowed, use spaces to align the diagram",
+	}, diagram.Errors(sb.String()))
+}
@@ -0,0 +1,273 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"go.abhg.dev/goldmark/frontmatter"
+)
+
+// Figures is a goldmark extension that renders an image with a title as a
+// figure when the image makes up an entire paragraph. The title of the image is
+// used as the caption of the figure.
+//
+// A figure can be given an id by following the image with {#fig:id}, e.g.
+//
+//	![A diagram](/images/diagram.png "How it works"){#fig:diagram}
+//
+// and referenced anywhere else in the document with @fig:diagram. If the
+// frontmatter of the document sets numberFigures to true, figures are numbered
+// in order and references read "Figure N". Otherwise, references use the
+// caption of the figure. References to figures that don't exist are left
+// as-is.
+type Figures struct{}
+
+// Extend adds the figure transformer, reference parser, and renderer to m.
+func (e *Figures) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithInlineParsers(
+			util.Prioritized(figureRefParser{}, 500),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(figureTransformer{}, 500),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(figureRenderer{}, 500),
+	))
+}
+
+var (
+	kindFigure    = ast.NewNodeKind("Figure")
+	kindFigureRef = ast.NewNodeKind("FigureRef")
+)
+
+var (
+	// figureIDPattern matches the id following an image in a figure.
+	figureIDPattern = regexp.MustCompile(`^\s*\{#fig:([\w-]+)\}\s*$`)
+
+	// figureRefPattern matches a reference to a figure.
+	figureRefPattern = regexp.MustCompile(`^@fig:([\w-]+)`)
+)
+
+// figureNode is a block AST node for a figure. Its only child is the image.
+type figureNode struct {
+	ast.BaseBlock
+
+	// The id of the figure without the fig: prefix. Empty if the figure
+	// doesn't have an id.
+	id string
+
+	// The caption of the figure. May be empty if the figure has an id.
+	caption []byte
+
+	// The number of the figure, starting at 1, or 0 if figures aren't
+	// numbered.
+	number int
+}
+
+func (n *figureNode) Kind() ast.NodeKind {
+	return kindFigure
+}
+
+func (n *figureNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"ID":      n.id,
+		"Caption": string(n.caption),
+		"Number":  strconv.Itoa(n.number),
+	}, nil)
+}
+
+// label returns the text used to refer to the figure.
+func (n *figureNode) label() []byte {
+	if n.number > 0 {
+		return []byte("Figure " + strconv.Itoa(n.number))
+	}
+	if len(n.caption) > 0 {
+		return n.caption
+	}
+	return []byte("figure")
+}
+
+// figureRefNode is an inline AST node for a reference to a figure.
+type figureRefNode struct {
+	ast.BaseInline
+
+	// The id of the referenced figure without the fig: prefix.
+	id string
+
+	// The referenced figure, or nil if there is no figure with the id.
+	figure *figureNode
+}
+
+func (n *figureRefNode) Kind() ast.NodeKind {
+	return kindFigureRef
+}
+
+func (n *figureRefNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"ID": n.id,
+	}, nil)
+}
+
+type figureRefParser struct{}
+
+func (figureRefParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (figureRefParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	// Don't mistake the domain of an email address for a reference.
+	if prev := block.PrecendingCharacter(); unicode.IsLetter(prev) || unicode.IsDigit(prev) {
+		return nil
+	}
+
+	line, _ := block.PeekLine()
+	m := figureRefPattern.FindSubmatch(line)
+	if m == nil {
+		return nil
+	}
+
+	block.Advance(len(m[0]))
+	return &figureRefNode{id: string(m[1])}
+}
+
+// figureTransformer replaces paragraphs that contain nothing but an image with
+// a title or id with figures, then resolves references to them.
+type figureTransformer struct{}
+
+func (figureTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var meta struct {
+		NumberFigures bool `yaml:"numberFigures"`
+	}
+	if fm := frontmatter.Get(pc); fm != nil {
+		// Invalid frontmatter is reported when the frontmatter is parsed, so
+		// it's safe to ignore here.
+		_ = fm.Decode(&meta)
+	}
+
+	var paragraphs []ast.Node
+	var refs []*figureRefNode
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if node.Kind() == ast.KindParagraph && node.ChildCount() > 0 {
+			if _, ok := node.FirstChild().(*ast.Image); ok {
+				paragraphs = append(paragraphs, node)
+			}
+		}
+		if ref, ok := node.(*figureRefNode); ok {
+			refs = append(refs, ref)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	figures := map[string]*figureNode{}
+	count := 0
+	for _, paragraph := range paragraphs {
+		image := paragraph.FirstChild().(*ast.Image)
+
+		// Anything after the image must be the id of the figure.
+		var rest []byte
+		for node := image.NextSibling(); node != nil; node = node.NextSibling() {
+			t, ok := node.(*ast.Text)
+			if !ok {
+				rest = nil
+				break
+			}
+			rest = append(rest, t.Value(reader.Source())...)
+		}
+		var id string
+		if m := figureIDPattern.FindSubmatch(rest); m != nil {
+			id = string(m[1])
+		} else if image.NextSibling() != nil || len(image.Title) == 0 {
+			continue
+		}
+
+		count++
+		figure := &figureNode{id: id, caption: image.Title}
+		if meta.NumberFigures {
+			figure.number = count
+		}
+		image.Title = nil
+		figure.AppendChild(figure, image)
+		paragraph.Parent().ReplaceChild(paragraph.Parent(), paragraph, figure)
+
+		if _, ok := figures[id]; id != "" && !ok {
+			figures[id] = figure
+		}
+	}
+
+	for _, ref := range refs {
+		ref.figure = figures[ref.id]
+	}
+}
+
+type figureRenderer struct{}
+
+func (figureRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindFigure, renderFigure)
+	reg.Register(kindFigureRef, renderFigureRef)
+}
+
+func renderFigure(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*figureNode)
+
+	if entering {
+		_, _ = w.WriteString("<figure")
+		if n.id != "" {
+			_, _ = w.WriteString(` id="fig:`)
+			_, _ = w.Write(util.EscapeHTML([]byte(n.id)))
+			_, _ = w.WriteString(`"`)
+		}
+		_, _ = w.WriteString(">\n")
+		return ast.WalkContinue, nil
+	}
+
+	var caption []byte
+	if n.number > 0 {
+		caption = n.label()
+		if len(n.caption) > 0 {
+			caption = append(caption, ": "...)
+		}
+	}
+	caption = append(caption, n.caption...)
+
+	if len(caption) > 0 {
+		_, _ = w.WriteString("\n<figcaption>")
+		_, _ = w.Write(util.EscapeHTML(caption))
+		_, _ = w.WriteString("</figcaption>")
+	}
+	_, _ = w.WriteString("\n</figure>\n")
+
+	return ast.WalkContinue, nil
+}
+
+func renderFigureRef(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*figureRefNode)
+	if n.figure == nil {
+		_, _ = w.WriteString("@fig:")
+		_, _ = w.Write(util.EscapeHTML([]byte(n.id)))
+		return ast.WalkSkipChildren, nil
+	}
+
+	_, _ = w.WriteString(`<a class="figure-ref" href="#fig:`)
+	_, _ = w.Write(util.EscapeHTML([]byte(n.id)))
+	_, _ = w.WriteString(`">`)
+	_, _ = w.Write(util.EscapeHTML(n.figure.label()))
+	_, _ = w.WriteString(`</a>`)
+
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,69 @@
+package markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/markdown"
+)
+
+func TestParse_Figures(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "caption",
+			source: `![A diagram](/images/diagram.png "How it works")`,
+			want:   "<figure>\n<img src=\"/images/diagram.png\" alt=\"A diagram\">\n<figcaption>How it works</figcaption>\n</figure>",
+		},
+		{
+			name:   "image without title",
+			source: `![A diagram](/images/diagram.png)`,
+			want:   `<p><img src="/images/diagram.png" alt="A diagram"></p>`,
+		},
+		{
+			name:   "image in text",
+			source: `See ![A diagram](/images/diagram.png "How it works") here.`,
+			want:   `<p>See <img src="/images/diagram.png" alt="A diagram" title="How it works"> here.</p>`,
+		},
+		{
+			name:   "id",
+			source: "![A diagram](/images/diagram.png \"How it works\"){#fig:diagram}\n\nAs @fig:diagram shows.",
+			want:   "<figure id=\"fig:diagram\">\n<img src=\"/images/diagram.png\" alt=\"A diagram\">\n<figcaption>How it works</figcaption>\n</figure>\n" + `<p>As <a class="figure-ref" href="#fig:diagram">How it works</a> shows.</p>`,
+		},
+		{
+			name:   "numbered",
+			source: "---\nnumberFigures: true\n---\nSee @fig:b.\n\n![A](/a.png \"First\")\n\n![B](/b.png){#fig:b}",
+			want:   `<p>See <a class="figure-ref" href="#fig:b">Figure 2</a>.</p>` + "\n<figure>\n<img src=\"/a.png\" alt=\"A\">\n<figcaption>Figure 1: First</figcaption>\n</figure>\n<figure id=\"fig:b\">\n<img src=\"/b.png\" alt=\"B\">\n<figcaption>Figure 2</figcaption>\n</figure>",
+		},
+		{
+			name:   "unknown reference",
+			source: "See @fig:missing.",
+			want:   `<p>See @fig:missing.</p>`,
+		},
+		{
+			name:   "email address",
+			source: "Email me@fig:diagram.",
+			want:   `<p>Email me@fig:diagram.</p>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			var sb strings.Builder
+			err = markdown.Parse(path, &sb)
+			assert.OK(t, err).Fatal()
+
+			assert.Equal(t, "html", tc.want, strings.TrimSpace(sb.String()))
+		})
+	}
+}
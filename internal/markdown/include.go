@@ -0,0 +1,189 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/haleyrc/stele/internal/snippet"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Includes is a goldmark extension that parses include directives, which pull
+// code from other files into the document:
+//
+//	{{< include "examples/server/main.go" region="handler" >}}
+//
+// The directive must take up a line of its own. It may select a region of the
+// file with region, a Go function with func, and override the language of the
+// code with lang.
+//
+// Each directive is rendered as a code block with the included code, loaded
+// by the Includer from the files of the site.
+type Includes struct {
+	// Loads the included code from files relative to its Root, which is the
+	// directory of the site. If nil, directives are rendered as empty pre
+	// elements with a data-include attribute naming the file.
+	Includer *snippet.Includer
+}
+
+// Extend adds the include parser and renderer to m.
+func (e *Includes) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(includeParser{}, 700),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&includeRenderer{ext: e}, 500),
+	))
+}
+
+var kindInclude = ast.NewNodeKind("Include")
+
+var (
+	// includePattern matches an include directive.
+	includePattern = regexp.MustCompile(`^\{\{<\s*include\s+"([^"]*)"((?:\s+\w+="[^"]*")*)\s*>\}\}$`)
+
+	// includeOptionPattern matches an option of an include directive.
+	includeOptionPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// includeOptions are the options an include directive accepts.
+var includeOptions = []string{"region", "func", "lang"}
+
+// includeNode is a block AST node for an include directive.
+type includeNode struct {
+	ast.BaseBlock
+
+	// The path of the included file, relative to the site.
+	path string
+
+	// The options of the directive by name.
+	options map[string]string
+
+	// The offset of the directive in the source.
+	offset int
+}
+
+func (n *includeNode) Kind() ast.NodeKind {
+	return kindInclude
+}
+
+func (n *includeNode) Dump(source []byte, level int) {
+	attrs := map[string]string{"Path": n.path}
+	for name, value := range n.options {
+		attrs[name] = value
+	}
+	ast.DumpHelper(n, source, level, attrs, nil)
+}
+
+// includeParser parses include directives.
+type includeParser struct{}
+
+func (includeParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (includeParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	m := includePattern.FindSubmatch(bytes.TrimSpace(line[pos:]))
+	if m == nil {
+		return nil, parser.NoChildren
+	}
+
+	node := &includeNode{
+		path:    string(m[1]),
+		options: map[string]string{},
+		offset:  segment.Start + pos,
+	}
+	for _, option := range includeOptionPattern.FindAllSubmatch(m[2], -1) {
+		node.options[string(option[1])] = string(option[2])
+	}
+
+	reader.AdvanceToEOL()
+	return node, parser.Close
+}
+
+func (includeParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	return parser.Close
+}
+
+func (includeParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (includeParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (includeParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+type includeRenderer struct {
+	ext *Includes
+}
+
+func (r *includeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindInclude, r.renderInclude)
+}
+
+func (r *includeRenderer) renderInclude(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*includeNode)
+	line := lineAt(source, n.offset)
+
+	if n.path == "" {
+		return ast.WalkStop, fmt.Errorf("line %d: include: missing path", line)
+	}
+	for name := range n.options {
+		if !slices.Contains(includeOptions, name) {
+			return ast.WalkStop, fmt.Errorf("line %d: include %q: unknown option %q", line, n.path, name)
+		}
+	}
+	if n.options["region"] != "" && n.options["func"] != "" {
+		return ast.WalkStop, fmt.Errorf("line %d: include %q: region and func can't be used together", line, n.path)
+	}
+
+	if r.ext.Includer == nil {
+		_, _ = w.WriteString(`<pre data-include="`)
+		_, _ = w.Write(util.EscapeHTML([]byte(n.path)))
+		_, _ = w.WriteString(`"></pre>` + "\n")
+		return ast.WalkContinue, nil
+	}
+
+	s := snippet.Snippet{
+		Path:   n.path,
+		Region: n.options["region"],
+		Func:   n.options["func"],
+		Lang:   n.options["lang"],
+	}
+	code, err := r.ext.Includer.Load(s)
+	if err != nil {
+		return ast.WalkStop, fmt.Errorf("line %d: %w", line, err)
+	}
+
+	_, _ = w.WriteString("<pre><code")
+	if lang := s.Language(); lang != "" {
+		_, _ = w.WriteString(` class="language-`)
+		_, _ = w.Write(util.EscapeHTML([]byte(lang)))
+		_, _ = w.WriteString(`"`)
+	}
+	_, _ = w.WriteString(">")
+	_, _ = w.Write(util.EscapeHTML([]byte(code)))
+	_, _ = w.WriteString("</code></pre>\n")
+
+	return ast.WalkContinue, nil
+}
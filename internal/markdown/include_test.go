@@ -0,0 +1,150 @@
+package markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/diagram"
+	"github.com/haleyrc/stele/internal/markdown"
+	"github.com/haleyrc/stele/internal/snippet"
+)
+
+func TestParse_Includes(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "file",
+			source: "Before.\n{{< include \"examples/main.go\" >}}\nAfter.",
+			want:   "<p>Before.</p>\n<pre data-include=\"examples/main.go\"></pre>\n<p>After.</p>",
+		},
+		{
+			name:   "options",
+			source: "{{<include \"a&b.js\" lang=\"ts\" region=\"setup\">}}",
+			want:   "<pre data-include=\"a&amp;b.js\"></pre>",
+		},
+		{
+			name:   "inline",
+			source: "See {{< include \"examples/main.go\" >}}.",
+			want:   "<p>See {{&lt; include &quot;examples/main.go&quot; &gt;}}.</p>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			var sb strings.Builder
+			err = markdown.Parse(path, &sb)
+			assert.OK(t, err).Fatal()
+
+			assert.Equal(t, "html", tc.want, strings.TrimSpace(sb.String()))
+		})
+	}
+}
+
+func TestParse_Includes_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		source string
+		want   string
+	}{
+		"missing path": {
+			source: "Text.\n\n{{< include \"\" >}}",
+			want:   `line 3: include: missing path`,
+		},
+		"unknown option": {
+			source: `{{< include "main.go" lines="1-5" >}}`,
+			want:   `line 1: include "main.go": unknown option "lines"`,
+		},
+		"region and func": {
+			source: `{{< include "main.go" region="a" func="b" >}}`,
+			want:   `line 1: include "main.go": region and func can't be used together`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			err = markdown.Parse(path, &strings.Builder{})
+			assert.Error(t, err, tc.want)
+		})
+	}
+}
+
+func TestDocument_RenderWith_Includes(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "examples"), 0750)
+	assert.OK(t, err).Fatal()
+	code := "// region: setup\nlet x = a < b;\n// endregion: setup\n+--+\n"
+	err = os.WriteFile(filepath.Join(dir, "examples", "app.js"), []byte(code), 0600)
+	assert.OK(t, err).Fatal()
+
+	testCases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "file",
+			source: "Before.\n{{< include \"examples/app.js\" >}}\nAfter.",
+			want:   "<p>Before.</p>\n<pre><code class=\"language-javascript\">let x = a &lt; b;\n+--+\n</code></pre>\n<p>After.</p>",
+		},
+		{
+			name:   "region",
+			source: `{{< include "examples/app.js" region="setup" lang="ts" >}}`,
+			want:   "<pre><code class=\"language-ts\">let x = a &lt; b;\n</code></pre>",
+		},
+		{
+			name:   "diagram language",
+			source: `{{< include "examples/app.js" lang="goat" >}}`,
+			want:   "<pre><code class=\"language-goat\">let x = a &lt; b;\n+--+\n</code></pre>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			doc, err := markdown.Load(path)
+			assert.OK(t, err).Fatal()
+
+			// Included code is never a diagram, even in the diagram language.
+			inc := snippet.NewIncluder(dir)
+			var sb strings.Builder
+			err = doc.RenderWith(&sb, markdown.RenderOptions{
+				Includes: markdown.Includes{Includer: inc},
+				Diagrams: markdown.Diagrams{Renderer: diagram.NewRenderer(t.TempDir())},
+			})
+			assert.OK(t, err).Fatal()
+
+			assert.Equal(t, "html", tc.want, strings.TrimSpace(sb.String()))
+			assert.Equal(t, "count", 1, inc.Count())
+		})
+	}
+}
+
+func TestDocument_RenderWith_Includes_Error(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "post.md")
+	err := os.WriteFile(path, []byte("Text.\n\n{{< include \"missing.go\" >}}"), 0600)
+	assert.OK(t, err).Fatal()
+
+	doc, err := markdown.Load(path)
+	assert.OK(t, err).Fatal()
+
+	err = doc.RenderWith(&strings.Builder{}, markdown.RenderOptions{
+		Includes: markdown.Includes{Includer: snippet.NewIncluder(t.TempDir())},
+	})
+	assert.Error(t, err, path+`: line 3: include "missing.go"`)
+}
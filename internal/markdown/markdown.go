@@ -9,18 +9,49 @@ import (
 
 	"github.com/yuin/goldmark"
 	emoji "github.com/yuin/goldmark-emoji"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
 	"go.abhg.dev/goldmark/frontmatter"
 )
 
-var defaultParser = goldmark.New(
-	goldmark.WithExtensions(
-		emoji.Emoji,
-		extension.GFM,
-		&frontmatter.Extender{},
-	),
-)
+// RenderOptions configures the parts of a document that depend on the rest of
+// the site it belongs to. The zero value renders wiki links without resolving
+// them, include directives as placeholders, and diagrams as code blocks.
+type RenderOptions struct {
+	// Resolves wiki links and embeds.
+	WikiLinks WikiLinks
+
+	// Loads the code selected by include directives.
+	Includes Includes
+
+	// Renders diagrams as SVG.
+	Diagrams Diagrams
+}
+
+// newParser creates a markdown parser with the default extensions, configured
+// by opts.
+func newParser(opts RenderOptions) goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithExtensions(
+			emoji.Emoji,
+			extension.GFM,
+			extension.Footnote,
+			&frontmatter.Extender{},
+			&Figures{},
+			&Math{},
+			&opts.Diagrams,
+			&Sidenotes{},
+			&Callouts{},
+			&opts.Includes,
+			&opts.WikiLinks,
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+	)
+}
 
 // Parse reads the file at path and writes the converted markdown content to w.
 func Parse(path string, w io.Writer) error {
@@ -29,30 +60,67 @@ func Parse(path string, w io.Writer) error {
 		return fmt.Errorf("markdown: parse: %s: %w", path, err)
 	}
 
-	if err := defaultParser.Convert(contents, w); err != nil {
+	if err := documentParser.Convert(contents, w); err != nil {
 		return fmt.Errorf("markdown: parse: %s: %w", path, err)
 	}
 
 	return nil
 }
 
-// ParseFrontmatter reads the file at path and attempts to populate fm with the
-// values found in the markdown frontmatter block.
-func ParseFrontmatter(path string, fm any) error {
-	ctx := parser.NewContext()
+// Document is a markdown file that has been parsed and can be rendered any
+// number of times without being parsed again. Wiki links, embeds, and include
+// directives are parsed along with everything else, so a document can be
+// rendered before the rest of the site is known, and then again with its
+// RenderOptions.
+type Document struct {
+	path   string
+	source []byte
+	root   ast.Node
+	ctx    parser.Context
+}
 
-	contents, err := os.ReadFile(path) // #nosec G304 - User-specified markdown file is intentional
+// documentParser parses documents and renders them with the zero
+// RenderOptions.
+var documentParser = newParser(RenderOptions{})
+
+// Load reads and parses the file at path.
+func Load(path string) (*Document, error) {
+	source, err := os.ReadFile(path) // #nosec G304 - User-specified markdown file is intentional
 	if err != nil {
-		return fmt.Errorf("markdown: parse frontmatter: %s: %w", path, err)
+		return nil, fmt.Errorf("markdown: load: %s: %w", path, err)
 	}
 
-	if err := defaultParser.Convert(contents, io.Discard, parser.WithContext(ctx)); err != nil {
-		return fmt.Errorf("markdown: parse frontmatter: %s: %w", path, err)
+	ctx := parser.NewContext()
+	doc := &Document{
+		path:   path,
+		source: source,
+		root:   documentParser.Parser().Parse(text.NewReader(source), parser.WithContext(ctx)),
+		ctx:    ctx,
 	}
+	return doc, nil
+}
 
-	if err := frontmatter.Get(ctx).Decode(fm); err != nil {
-		return fmt.Errorf("markdown: parse frontmatter: %s: %w", path, err)
+// Frontmatter populates fm with the values found in the frontmatter block of
+// the document.
+func (d *Document) Frontmatter(fm any) error {
+	if err := frontmatter.Get(d.ctx).Decode(fm); err != nil {
+		return fmt.Errorf("markdown: frontmatter: %s: %w", d.path, err)
 	}
+	return nil
+}
 
+// Render writes the document as HTML to w with the zero RenderOptions.
+func (d *Document) Render(w io.Writer) error {
+	if err := documentParser.Renderer().Render(w, d.source, d.root); err != nil {
+		return fmt.Errorf("markdown: render: %s: %w", d.path, err)
+	}
+	return nil
+}
+
+// RenderWith is like Render, but renders the document with opts.
+func (d *Document) RenderWith(w io.Writer, opts RenderOptions) error {
+	if err := newParser(opts).Renderer().Render(w, d.source, d.root); err != nil {
+		return fmt.Errorf("markdown: render: %s: %w", d.path, err)
+	}
 	return nil
 }
@@ -0,0 +1,294 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/haleyrc/stele/internal/mathml"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Math is a goldmark extension that renders LaTeX math as MathML. Inline math
+// is written between single dollar signs, e.g. $e^{i\pi} + 1 = 0$, and display
+// math between double dollar signs, either inline or on lines of their own:
+//
+//	$$
+//	\sum_{i=1}^n i = \frac{n(n+1)}{2}
+//	$$
+//
+// So that prices in prose aren't mistaken for math, an opening dollar sign
+// must be followed by a non-space character and a closing dollar sign must be
+// preceded by a non-space character and not followed by a digit. A dollar sign
+// can also be escaped with a backslash.
+//
+// Expressions that can't be converted are reported as errors with the line of
+// the source they appear on.
+type Math struct{}
+
+// Extend adds the math parsers and renderer to m.
+func (e *Math) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(mathBlockParser{}, 700),
+		),
+		parser.WithInlineParsers(
+			util.Prioritized(mathParser{}, 500),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(mathRenderer{}, 500),
+	))
+}
+
+var (
+	kindMath      = ast.NewNodeKind("Math")
+	kindMathBlock = ast.NewNodeKind("MathBlock")
+)
+
+// mathNode is an inline AST node for a math expression.
+type mathNode struct {
+	ast.BaseInline
+
+	// The LaTeX expression without its delimiters.
+	tex []byte
+
+	// Whether the expression is display math.
+	display bool
+
+	// The offset of the expression in the source.
+	offset int
+}
+
+func (n *mathNode) Kind() ast.NodeKind {
+	return kindMath
+}
+
+func (n *mathNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"TeX":     string(n.tex),
+		"Display": fmt.Sprint(n.display),
+	}, nil)
+}
+
+// mathBlockNode is a block AST node for display math on lines of its own. Its
+// lines are the lines of the expression.
+type mathBlockNode struct {
+	ast.BaseBlock
+
+	// The offset of the opening delimiter in the source.
+	offset int
+
+	// Whether the closing delimiter was found.
+	closed bool
+}
+
+func (n *mathBlockNode) Kind() ast.NodeKind {
+	return kindMathBlock
+}
+
+func (n *mathBlockNode) IsRaw() bool {
+	return true
+}
+
+func (n *mathBlockNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type mathParser struct{}
+
+func (mathParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (mathParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+
+	delim := []byte("$")
+	if bytes.HasPrefix(line, []byte("$$")) {
+		delim = []byte("$$")
+	}
+
+	rest := line[len(delim):]
+	if len(rest) == 0 || isSpace(rest[0]) && len(delim) == 1 {
+		return nil
+	}
+
+	end := -1
+	for i := 1; i < len(rest); i++ {
+		if rest[i] == '\\' {
+			i++
+			continue
+		}
+		if !bytes.HasPrefix(rest[i:], delim) {
+			continue
+		}
+		if len(delim) == 1 && (isSpace(rest[i-1]) || i+1 < len(rest) && isDigit(rest[i+1])) {
+			continue
+		}
+		end = i
+		break
+	}
+	if end < 0 {
+		return nil
+	}
+
+	block.Advance(len(delim) + end + len(delim))
+	return &mathNode{
+		tex:     rest[:end],
+		display: len(delim) == 2,
+		offset:  segment.Start + len(delim),
+	}
+}
+
+// mathBlockParser parses display math whose delimiters are on lines of their
+// own, or which takes up an entire line.
+type mathBlockParser struct{}
+
+func (mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	rest := bytes.TrimSpace(line[pos:])
+	if !bytes.HasPrefix(rest, []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+
+	node := &mathBlockNode{offset: segment.Start + pos}
+	if len(rest) == 2 {
+		reader.AdvanceToEOL()
+		return node, parser.NoChildren
+	}
+
+	// Display math on a single line is only a block if nothing follows it.
+	inner := rest[2:]
+	if len(inner) < 3 || !bytes.HasSuffix(inner, []byte("$$")) || bytes.Contains(inner[:len(inner)-2], []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+	start := segment.Start + pos + bytes.Index(line[pos:], []byte("$$")) + 2
+	node.Lines().Append(text.NewSegment(start, start+len(inner)-2))
+	node.closed = true
+	reader.AdvanceToEOL()
+	return node, parser.Close
+}
+
+func (mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	trimmed := bytes.TrimRight(line, " \t\r\n")
+
+	if bytes.HasSuffix(trimmed, []byte("$$")) {
+		content := trimmed[:len(trimmed)-2]
+		if len(bytes.TrimSpace(content)) > 0 {
+			node.Lines().Append(text.NewSegment(segment.Start, segment.Start+len(content)))
+		}
+		node.(*mathBlockNode).closed = true
+		reader.AdvanceToEOL()
+		return parser.Close
+	}
+
+	node.Lines().Append(segment)
+	reader.AdvanceToEOL()
+	return parser.Continue | parser.NoChildren
+}
+
+func (mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+type mathRenderer struct{}
+
+func (mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, renderMath)
+	reg.Register(kindMathBlock, renderMathBlock)
+}
+
+func renderMath(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*mathNode)
+	if err := writeMath(w, source, n.tex, n.offset, n.display); err != nil {
+		return ast.WalkStop, err
+	}
+
+	return ast.WalkSkipChildren, nil
+}
+
+func renderMathBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*mathBlockNode)
+	if !n.closed {
+		return ast.WalkStop, fmt.Errorf("line %d: math: unclosed $$", lineAt(source, n.offset))
+	}
+
+	var tex []byte
+	offset := n.offset
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		if i == 0 {
+			offset = segment.Start
+		}
+		tex = append(tex, segment.Value(source)...)
+	}
+
+	if err := writeMath(w, source, tex, offset, true); err != nil {
+		return ast.WalkStop, err
+	}
+	_, _ = w.WriteString("\n")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// writeMath converts tex to MathML and writes it to w. The offset of tex in
+// source is used to report the line of any error in the expression.
+func writeMath(w util.BufWriter, source, tex []byte, offset int, display bool) error {
+	ml, err := mathml.Convert(string(bytes.TrimSpace(tex)), display)
+	if err != nil {
+		line := lineAt(source, offset)
+		var mathErr *mathml.Error
+		if errors.As(err, &mathErr) {
+			leading := len(tex) - len(bytes.TrimLeft(tex, " \t\r\n"))
+			line += bytes.Count(tex[:min(leading+mathErr.Offset, len(tex))], []byte("\n"))
+		}
+		return fmt.Errorf("line %d: math %q: %w", line, bytes.TrimSpace(tex), err)
+	}
+
+	_, _ = w.WriteString(ml)
+	return nil
+}
+
+// lineAt returns the line number, starting at 1, of offset in source.
+func lineAt(source []byte, offset int) int {
+	return bytes.Count(source[:offset], []byte("\n")) + 1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
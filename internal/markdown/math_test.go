@@ -0,0 +1,110 @@
+package markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/markdown"
+)
+
+func TestParse_Math(t *testing.T) {
+	const (
+		inlineX  = `<math xmlns="http://www.w3.org/1998/Math/MathML"><semantics><mi>x</mi><annotation encoding="application/x-tex">x</annotation></semantics></math>`
+		displayX = `<math xmlns="http://www.w3.org/1998/Math/MathML" display="block"><semantics><mi>x</mi><annotation encoding="application/x-tex">x</annotation></semantics></math>`
+	)
+
+	testCases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "inline",
+			source: "Let $x$ be.",
+			want:   "<p>Let " + inlineX + " be.</p>",
+		},
+		{
+			name:   "display block",
+			source: "Then\n$$\nx\n$$\nholds.",
+			want:   "<p>Then</p>\n" + displayX + "\n<p>holds.</p>",
+		},
+		{
+			name:   "display line",
+			source: "$$ x $$",
+			want:   displayX,
+		},
+		{
+			name:   "prices",
+			source: "It costs $5 or $10.",
+			want:   "<p>It costs $5 or $10.</p>",
+		},
+		{
+			name:   "spaces",
+			source: "Between $ and $ signs.",
+			want:   "<p>Between $ and $ signs.</p>",
+		},
+		{
+			name:   "escaped",
+			source: `Pay \$5 for $x$.`,
+			want:   "<p>Pay $5 for " + inlineX + ".</p>",
+		},
+		{
+			name:   "code",
+			source: "Use `$x$` to write math.",
+			want:   "<p>Use <code>$x$</code> to write math.</p>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			var sb strings.Builder
+			err = markdown.Parse(path, &sb)
+			assert.OK(t, err).Fatal()
+
+			assert.Equal(t, "html", tc.want, strings.TrimSpace(sb.String()))
+		})
+	}
+}
+
+func TestParse_Math_Errors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "inline",
+			source: "---\ntitle: Math\n---\nFirst.\n\nSee $\\frac{1}$.",
+			want:   `line 6: math "\\frac{1}": missing argument`,
+		},
+		{
+			name:   "display",
+			source: "$$\nx + 1\n\\foo\n$$",
+			want:   `line 3: math "x + 1\n\\foo": unknown command \foo`,
+		},
+		{
+			name:   "unclosed",
+			source: "Text.\n\n$$\nx",
+			want:   "line 3: math: unclosed $$",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			var sb strings.Builder
+			err = markdown.Parse(path, &sb)
+			assert.Error(t, err, path+": "+tc.want)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package markdown
+
+import (
+	"strconv"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+	"go.abhg.dev/goldmark/frontmatter"
+)
+
+// Sidenotes is a goldmark extension that moves footnotes next to the text that
+// references them when the frontmatter of the document sets sidenotes to true.
+// It must be used along with the footnote extension.
+//
+// Each sidenote is rendered after its reference as a span with the sidenote
+// class, preceded by a label and a checkbox with the sidenote-number and
+// sidenote-toggle classes. This lets stylesheets show sidenotes in the margin
+// on wide screens and toggle them inline on narrow screens without any
+// scripts. Footnotes that contain anything other than paragraphs, such as
+// lists or code blocks, can't be shown inline and are left as footnotes.
+type Sidenotes struct{}
+
+// Extend adds the sidenote transformer and renderer to m.
+func (e *Sidenotes) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithASTTransformers(
+			// Runs after the footnote transformer has numbered the footnotes.
+			util.Prioritized(sidenoteTransformer{}, 1000),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(sidenoteRenderer{}, 500),
+	))
+}
+
+var kindSidenote = ast.NewNodeKind("Sidenote")
+
+// sidenoteNode is an inline AST node for a reference to a footnote shown as a
+// sidenote. The first reference to a footnote has the contents of the
+// footnote as its children. Later references have no children.
+type sidenoteNode struct {
+	ast.BaseInline
+
+	// The number of the footnote.
+	index int
+}
+
+func (n *sidenoteNode) Kind() ast.NodeKind {
+	return kindSidenote
+}
+
+func (n *sidenoteNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Index": strconv.Itoa(n.index),
+	}, nil)
+}
+
+// sidenoteTransformer replaces references to footnotes made up of paragraphs
+// with sidenotes and removes those footnotes from the list of footnotes.
+type sidenoteTransformer struct{}
+
+func (sidenoteTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var meta struct {
+		Sidenotes bool `yaml:"sidenotes"`
+	}
+	if fm := frontmatter.Get(pc); fm != nil {
+		// Invalid frontmatter is reported when the frontmatter is parsed, so
+		// it's safe to ignore here.
+		_ = fm.Decode(&meta)
+	}
+	if !meta.Sidenotes {
+		return
+	}
+
+	var list *east.FootnoteList
+	var links []*east.FootnoteLink
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *east.FootnoteList:
+			list = n
+		case *east.FootnoteLink:
+			links = append(links, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	if list == nil {
+		return
+	}
+
+	footnotes := map[int]*east.Footnote{}
+	for node := list.FirstChild(); node != nil; node = node.NextSibling() {
+		footnote := node.(*east.Footnote)
+		if onlyParagraphs(footnote) {
+			footnotes[footnote.Index] = footnote
+		}
+	}
+
+	seen := map[int]bool{}
+	for _, link := range links {
+		footnote, ok := footnotes[link.Index]
+		if !ok {
+			continue
+		}
+
+		sidenote := &sidenoteNode{index: link.Index}
+		if !seen[link.Index] {
+			seen[link.Index] = true
+			for paragraph := footnote.FirstChild(); paragraph != nil; paragraph = paragraph.NextSibling() {
+				if paragraph != footnote.FirstChild() {
+					br := ast.NewText()
+					br.SetHardLineBreak(true)
+					sidenote.AppendChild(sidenote, br)
+				}
+				for child := paragraph.FirstChild(); child != nil; {
+					next := child.NextSibling()
+					if child.Kind() != east.KindFootnoteBacklink {
+						sidenote.AppendChild(sidenote, child)
+					}
+					child = next
+				}
+			}
+		}
+		link.Parent().ReplaceChild(link.Parent(), link, sidenote)
+	}
+
+	for _, footnote := range footnotes {
+		list.RemoveChild(list, footnote)
+		list.Count--
+	}
+	if list.ChildCount() == 0 {
+		list.Parent().RemoveChild(list.Parent(), list)
+	}
+}
+
+// onlyParagraphs returns true if every child of node is a paragraph.
+func onlyParagraphs(node ast.Node) bool {
+	if node.ChildCount() == 0 {
+		return false
+	}
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if child.Kind() != ast.KindParagraph {
+			return false
+		}
+	}
+	return true
+}
+
+type sidenoteRenderer struct{}
+
+func (sidenoteRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindSidenote, renderSidenote)
+}
+
+func renderSidenote(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*sidenoteNode)
+	index := strconv.Itoa(n.index)
+
+	if !entering {
+		if n.HasChildren() {
+			_, _ = w.WriteString("</span>")
+		}
+		return ast.WalkContinue, nil
+	}
+
+	_, _ = w.WriteString(`<label for="sn:` + index + `" class="sidenote-number">` + index + `</label>`)
+	if !n.HasChildren() {
+		return ast.WalkContinue, nil
+	}
+
+	_, _ = w.WriteString(`<input type="checkbox" id="sn:` + index + `" class="sidenote-toggle">`)
+	_, _ = w.WriteString(`<span class="sidenote" role="note"><span class="sidenote-number">` + index + `</span> `)
+
+	return ast.WalkContinue, nil
+}
@@ -0,0 +1,57 @@
+package markdown_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/markdown"
+)
+
+func TestParse_Footnotes(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			name:   "footnotes",
+			source: "A claim.[^1]\n\n[^1]: A *source*.",
+			want: `<p>A claim.<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a></sup></p>` + "\n" +
+				`<div class="footnotes" role="doc-endnotes">` + "\n<hr>\n<ol>\n" + `<li id="fn:1">` + "\n" +
+				`<p>A <em>source</em>.&#160;<a href="#fnref:1" class="footnote-backref" role="doc-backlink">&#x21a9;&#xfe0e;</a></p>` + "\n</li>\n</ol>\n</div>",
+		},
+		{
+			name:   "sidenotes",
+			source: "---\nsidenotes: true\n---\nA claim.[^1] Again.[^1]\n\n[^1]: A *source*.\n\n    More.",
+			want: `<p>A claim.<label for="sn:1" class="sidenote-number">1</label><input type="checkbox" id="sn:1" class="sidenote-toggle">` +
+				`<span class="sidenote" role="note"><span class="sidenote-number">1</span> A <em>source</em>.<br>` + "\n" + `More.</span>` +
+				` Again.<label for="sn:1" class="sidenote-number">1</label></p>`,
+		},
+		{
+			name:   "sidenotes with blocks",
+			source: "---\nsidenotes: true\n---\nOne.[^a] Two.[^b]\n\n[^a]: Inline.\n[^b]: - A list",
+			want: `<p>One.<label for="sn:1" class="sidenote-number">1</label><input type="checkbox" id="sn:1" class="sidenote-toggle">` +
+				`<span class="sidenote" role="note"><span class="sidenote-number">1</span> Inline.</span>` +
+				` Two.<sup id="fnref:2"><a href="#fn:2" class="footnote-ref" role="doc-noteref">2</a></sup></p>` + "\n" +
+				`<div class="footnotes" role="doc-endnotes">` + "\n<hr>\n<ol>\n" + `<li id="fn:2">` + "\n<ul>\n<li>A list</li>\n</ul>\n" +
+				`&#160;<a href="#fnref:2" class="footnote-backref" role="doc-backlink">&#x21a9;&#xfe0e;</a></li>` + "\n</ol>\n</div>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "post.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			var sb strings.Builder
+			err = markdown.Parse(path, &sb)
+			assert.OK(t, err).Fatal()
+
+			assert.Equal(t, "html", tc.want, strings.TrimSpace(sb.String()))
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// WikiLinkResolver looks up the target of a wiki link, which is the slug of a
+// note or post, and returns its URL and title. The final return value is false
+// if the target does not exist.
+type WikiLinkResolver func(target string) (url, title string, ok bool)
+
+// WikiEmbedResolver returns the rendered HTML of the note with the given slug
+// for embedding in another document. If heading is not empty, only the section
+// under the heading with that id is returned. The second return value is false
+// if the note or heading does not exist.
+type WikiEmbedResolver func(target, heading string) (html string, ok bool, err error)
+
+// WikiLink is a wiki link or embed found while parsing a document.
+type WikiLink struct {
+	// The slug the link refers to, without any label or heading fragment.
+	Target string
+
+	// The heading fragment of the link, if any.
+	Fragment string
+
+	// Whether the link is an embed.
+	Embed bool
+
+	// Whether the target was found by the resolver.
+	Resolved bool
+}
+
+// WikiLinks is a goldmark extension that converts [[target]] and
+// [[target|label]] into links. Targets may include a heading fragment, e.g.
+// [[target#heading]]. Links without a label use the title of the target.
+//
+// A paragraph containing only ![[target]] or ![[target#heading]] embeds the
+// content of the target note in place, followed by a link back to it. Embeds
+// anywhere else are rendered as regular wiki links.
+//
+// Unresolved targets are still rendered as links to the note they would refer
+// to so that they are caught by link checking.
+type WikiLinks struct {
+	// Resolves link targets to URLs and titles.
+	Resolver WikiLinkResolver
+
+	// Resolves embedded notes to their content. Embeds are rendered as
+	// regular wiki links if nil.
+	Embedder WikiEmbedResolver
+
+	// Called for each wiki link as it is rendered. Optional.
+	OnLink func(WikiLink)
+}
+
+// Extend adds the wiki link parser and renderer to m.
+func (e *WikiLinks) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithInlineParsers(
+			// Must run before the standard link parser, which also triggers
+			// on [ and !.
+			util.Prioritized(wikiLinkParser{}, 199),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(wikiEmbedTransformer{}, 500),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(&wikiLinkRenderer{ext: e}, 500),
+	))
+}
+
+var (
+	kindWikiLink  = ast.NewNodeKind("WikiLink")
+	kindWikiEmbed = ast.NewNodeKind("WikiEmbed")
+)
+
+// wikiLinkNode is an inline AST node for a wiki link.
+type wikiLinkNode struct {
+	ast.BaseInline
+
+	target   string
+	fragment string
+	label    string
+
+	// Whether the link was written as an embed.
+	embed bool
+}
+
+func (n *wikiLinkNode) Kind() ast.NodeKind {
+	return kindWikiLink
+}
+
+func (n *wikiLinkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Target":   n.target,
+		"Fragment": n.fragment,
+		"Label":    n.label,
+	}, nil)
+}
+
+// wikiEmbedNode is a block AST node for an embed that makes up an entire
+// paragraph.
+type wikiEmbedNode struct {
+	ast.BaseBlock
+
+	link *wikiLinkNode
+}
+
+func (n *wikiEmbedNode) Kind() ast.NodeKind {
+	return kindWikiEmbed
+}
+
+func (n *wikiEmbedNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Target":   n.link.target,
+		"Fragment": n.link.fragment,
+	}, nil)
+}
+
+type wikiLinkParser struct{}
+
+func (wikiLinkParser) Trigger() []byte {
+	return []byte{'[', '!'}
+}
+
+func (wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+
+	embed := bytes.HasPrefix(line, []byte("!"))
+	if embed {
+		line = line[1:]
+	}
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+
+	end := bytes.Index(line, []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+
+	node := parseWikiLink(string(line[2:end]))
+	if node == nil {
+		return nil
+	}
+	node.embed = embed
+
+	if embed {
+		block.Advance(1)
+	}
+	block.Advance(end + 2)
+	return node
+}
+
+// wikiEmbedTransformer replaces paragraphs that contain nothing but an embed
+// with the embedded content.
+type wikiEmbedTransformer struct{}
+
+func (wikiEmbedTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var paragraphs []ast.Node
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && node.Kind() == ast.KindParagraph && node.ChildCount() == 1 {
+			if link, ok := node.FirstChild().(*wikiLinkNode); ok && link.embed {
+				paragraphs = append(paragraphs, node)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, paragraph := range paragraphs {
+		embed := &wikiEmbedNode{link: paragraph.FirstChild().(*wikiLinkNode)}
+		paragraph.Parent().ReplaceChild(paragraph.Parent(), paragraph, embed)
+	}
+}
+
+// parseWikiLink parses the contents of a wiki link between the brackets.
+// Returns nil if there is no target.
+func parseWikiLink(s string) *wikiLinkNode {
+	s, label, _ := strings.Cut(s, "|")
+	target, fragment, _ := strings.Cut(s, "#")
+
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil
+	}
+
+	return &wikiLinkNode{
+		target:   target,
+		fragment: strings.TrimSpace(fragment),
+		label:    strings.TrimSpace(label),
+	}
+}
+
+type wikiLinkRenderer struct {
+	ext *WikiLinks
+}
+
+func (r *wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindWikiLink, r.renderLink)
+	reg.Register(kindWikiEmbed, r.renderEmbed)
+}
+
+func (r *wikiLinkRenderer) renderLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*wikiLinkNode)
+	ok := r.writeLink(w, n, n.label)
+	r.onLink(WikiLink{Target: n.target, Fragment: n.fragment, Resolved: ok})
+
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *wikiLinkRenderer) renderEmbed(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*wikiEmbedNode).link
+
+	var content string
+	ok := false
+	if r.ext.Embedder != nil {
+		var err error
+		content, ok, err = r.ext.Embedder(n.target, n.fragment)
+		if err != nil {
+			return ast.WalkStop, err
+		}
+	}
+
+	if !ok {
+		_, _ = w.WriteString("<p>")
+		r.writeLink(w, n, n.label)
+		_, _ = w.WriteString("</p>\n")
+	} else {
+		_, _ = w.WriteString(`<div class="embed border-l-4 pl-3 my-2">` + "\n")
+		_, _ = w.WriteString(content)
+		_, _ = w.WriteString(`<div class="embed-source text-xs font-extralight">From `)
+		r.writeLink(w, n, n.label)
+		_, _ = w.WriteString("</div>\n</div>\n")
+	}
+	r.onLink(WikiLink{Target: n.target, Fragment: n.fragment, Embed: true, Resolved: ok})
+
+	return ast.WalkSkipChildren, nil
+}
+
+// writeLink writes an anchor for the wiki link with the given label, or the
+// title of the target if the label is empty. Returns false if the target could
+// not be resolved.
+func (r *wikiLinkRenderer) writeLink(w util.BufWriter, n *wikiLinkNode, label string) bool {
+	var url, title string
+	ok := false
+	if r.ext.Resolver != nil {
+		url, title, ok = r.ext.Resolver(n.target)
+	}
+	if !ok {
+		url, title = "/notes/"+n.target, n.target
+	}
+
+	if n.fragment != "" {
+		url += "#" + n.fragment
+	}
+	if label == "" {
+		label = title
+	}
+
+	_, _ = w.WriteString(`<a class="wikilink" href="`)
+	_, _ = w.Write(util.EscapeHTML(util.URLEscape([]byte(url), true)))
+	_, _ = w.WriteString(`">`)
+	_, _ = w.Write(util.EscapeHTML([]byte(label)))
+	_, _ = w.WriteString(`</a>`)
+
+	return ok
+}
+
+func (r *wikiLinkRenderer) onLink(link WikiLink) {
+	if r.ext.OnLink != nil {
+		r.ext.OnLink(link)
+	}
+}
@@ -0,0 +1,178 @@
+package markdown_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/markdown"
+)
+
+func resolve(target string) (string, string, bool) {
+	if target == "vim" {
+		return "/notes/vim", "Vim Tips", true
+	}
+	return "", "", false
+}
+
+func embed(target, heading string) (string, bool, error) {
+	if target == "vim" {
+		return "<p>Use :wq</p>", true, nil
+	}
+	return "", false, nil
+}
+
+// renderWikiLinks renders doc to w with wiki links resolved by resolve and
+// embeds by embed, and returns the links it contains.
+func renderWikiLinks(doc *markdown.Document, w io.Writer, resolve markdown.WikiLinkResolver, embed markdown.WikiEmbedResolver) ([]markdown.WikiLink, error) {
+	var links []markdown.WikiLink
+	err := doc.RenderWith(w, markdown.RenderOptions{
+		WikiLinks: markdown.WikiLinks{
+			Resolver: resolve,
+			Embedder: embed,
+			OnLink:   func(link markdown.WikiLink) { links = append(links, link) },
+		},
+	})
+	return links, err
+}
+
+func TestDocument_RenderWith_EmbedError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+	err := os.WriteFile(path, []byte("![[vim]]"), 0600)
+	assert.OK(t, err).Fatal()
+
+	doc, err := markdown.Load(path)
+	assert.OK(t, err).Fatal()
+
+	_, err = renderWikiLinks(doc, io.Discard, resolve, func(target, heading string) (string, bool, error) {
+		return "", false, errors.New("embed cycle")
+	})
+	assert.Error(t, err, "embed cycle")
+}
+
+func TestDocument_RenderWith_WikiLinks(t *testing.T) {
+	testCases := []struct {
+		name   string
+		source string
+		want   string
+		links  []markdown.WikiLink
+	}{
+		{
+			name:   "title as label",
+			source: "See [[vim]].",
+			want:   `<p>See <a class="wikilink" href="/notes/vim">Vim Tips</a>.</p>`,
+			links:  []markdown.WikiLink{{Target: "vim", Resolved: true}},
+		},
+		{
+			name:   "custom label",
+			source: "See [[vim|my notes]].",
+			want:   `<p>See <a class="wikilink" href="/notes/vim">my notes</a>.</p>`,
+			links:  []markdown.WikiLink{{Target: "vim", Resolved: true}},
+		},
+		{
+			name:   "heading fragment",
+			source: "See [[vim#modes|modes]].",
+			want:   `<p>See <a class="wikilink" href="/notes/vim#modes">modes</a>.</p>`,
+			links:  []markdown.WikiLink{{Target: "vim", Fragment: "modes", Resolved: true}},
+		},
+		{
+			name:   "unresolved",
+			source: "See [[emacs]].",
+			want:   `<p>See <a class="wikilink" href="/notes/emacs">emacs</a>.</p>`,
+			links:  []markdown.WikiLink{{Target: "emacs", Resolved: false}},
+		},
+		{
+			name:   "regular link",
+			source: "See [vim](/notes/vim).",
+			want:   `<p>See <a href="/notes/vim">vim</a>.</p>`,
+		},
+		{
+			name:   "code span",
+			source: "Write `[[vim]]`.",
+			want:   `<p>Write <code>[[vim]]</code>.</p>`,
+		},
+		{
+			name:   "embed",
+			source: "Before.\n\n![[vim]]\n\nAfter.",
+			want:   "<p>Before.</p>\n<div class=\"embed border-l-4 pl-3 my-2\">\n<p>Use :wq</p><div class=\"embed-source text-xs font-extralight\">From <a class=\"wikilink\" href=\"/notes/vim\">Vim Tips</a></div>\n</div>\n<p>After.</p>",
+			links:  []markdown.WikiLink{{Target: "vim", Embed: true, Resolved: true}},
+		},
+		{
+			name:   "embed section",
+			source: "![[vim#quitting|Quitting Vim]]",
+			want:   "<div class=\"embed border-l-4 pl-3 my-2\">\n<p>Use :wq</p><div class=\"embed-source text-xs font-extralight\">From <a class=\"wikilink\" href=\"/notes/vim#quitting\">Quitting Vim</a></div>\n</div>",
+			links:  []markdown.WikiLink{{Target: "vim", Fragment: "quitting", Embed: true, Resolved: true}},
+		},
+		{
+			name:   "unresolved embed",
+			source: "![[emacs]]",
+			want:   `<p><a class="wikilink" href="/notes/emacs">emacs</a></p>`,
+			links:  []markdown.WikiLink{{Target: "emacs", Embed: true}},
+		},
+		{
+			name:   "inline embed",
+			source: "See ![[vim]] here.",
+			want:   `<p>See <a class="wikilink" href="/notes/vim">Vim Tips</a> here.</p>`,
+			links:  []markdown.WikiLink{{Target: "vim", Resolved: true}},
+		},
+		{
+			name:   "image",
+			source: "![cat](/cat.png)",
+			want:   `<p><img src="/cat.png" alt="cat"></p>`,
+		},
+		{
+			name:   "empty target",
+			source: "Empty [[ ]] link.",
+			want:   `<p>Empty [[ ]] link.</p>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "note.md")
+			err := os.WriteFile(path, []byte(tc.source), 0600)
+			assert.OK(t, err).Fatal()
+
+			doc, err := markdown.Load(path)
+			assert.OK(t, err).Fatal()
+
+			var sb strings.Builder
+			links, err := renderWikiLinks(doc, &sb, resolve, embed)
+			assert.OK(t, err).Fatal()
+
+			assert.Equal(t, "html", tc.want, strings.TrimSpace(sb.String()))
+			assert.SliceEqual(t, "links", tc.links, links)
+		})
+	}
+}
+
+func TestDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+	err := os.WriteFile(path, []byte("---\ntitle: Editors\n---\nSee [[vim]].\n"), 0600)
+	assert.OK(t, err).Fatal()
+
+	doc, err := markdown.Load(path)
+	assert.OK(t, err).Fatal()
+
+	var fm struct {
+		Title string `yaml:"title"`
+	}
+	assert.OK(t, doc.Frontmatter(&fm)).Fatal()
+	assert.Equal(t, "title", "Editors", fm.Title)
+
+	// The same document renders with and without the targets resolved.
+	var sb strings.Builder
+	assert.OK(t, doc.Render(&sb)).Fatal()
+	assert.Equal(t, "unresolved", `<p>See <a class="wikilink" href="/notes/vim">vim</a>.</p>`, strings.TrimSpace(sb.String()))
+
+	sb.Reset()
+	links, err := renderWikiLinks(doc, &sb, resolve, embed)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "resolved", `<p>See <a class="wikilink" href="/notes/vim">Vim Tips</a>.</p>`, strings.TrimSpace(sb.String()))
+	assert.Equal(t, "link count", 1, len(links))
+	assert.True(t, "link resolved", links[0].Resolved)
+}
@@ -0,0 +1,744 @@
+// Package mathml converts LaTeX math expressions to MathML.
+//
+// Only the subset of LaTeX commonly used in prose is supported: letters,
+// numbers, and operators; superscripts and subscripts; fractions and roots;
+// stretchy delimiters; accents; font commands; text; spacing; and matrix,
+// cases, and aligned environments, along with the usual symbols and Greek
+// letters. Anything else is reported as an error rather than being guessed at.
+package mathml
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error is an error in a LaTeX expression.
+type Error struct {
+	// The byte offset in the expression at which the error was found.
+	Offset int
+
+	// A description of the error.
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Msg, e.Offset)
+}
+
+// Convert converts a LaTeX math expression to a MathML math element. Display
+// expressions are rendered as blocks and inline expressions as part of the
+// surrounding text. The original expression is included as an annotation.
+func Convert(tex string, display bool) (string, error) {
+	p := &parser{src: tex, display: display}
+
+	// Lines separated by \\ are stacked, as in the gathered environment.
+	var lines []string
+	for {
+		nodes, err := p.parseList()
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, mrow(nodes))
+
+		if p.atEnd() {
+			break
+		}
+		if p.peekCommand() != `\` {
+			return "", p.unexpected()
+		}
+		p.command()
+	}
+
+	body := lines[0]
+	if len(lines) > 1 {
+		body = "<mtable><mtr><mtd>" + strings.Join(lines, "</mtd></mtr><mtr><mtd>") + "</mtd></mtr></mtable>"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if display {
+		sb.WriteString(` display="block"`)
+	}
+	sb.WriteString("><semantics>")
+	sb.WriteString(body)
+	sb.WriteString(`<annotation encoding="application/x-tex">`)
+	sb.WriteString(html.EscapeString(tex))
+	sb.WriteString("</annotation></semantics></math>")
+	return sb.String(), nil
+}
+
+// atom is a parsed element along with how scripts are attached to it.
+type atom struct {
+	// The MathML for the element.
+	ml string
+
+	// Whether scripts are placed above and below the element in display
+	// mode, as for \sum and \lim.
+	limits bool
+
+	// Whether scripts are always placed above and below the element, as for
+	// \underbrace.
+	under bool
+}
+
+type parser struct {
+	src     string
+	pos     int
+	display bool
+}
+
+// parseList parses elements until the end of the expression or a token that
+// ends a group: }, &, \\, \right, or \end.
+func (p *parser) parseList() ([]string, error) {
+	var nodes []string
+	for {
+		p.skipSpace()
+		if p.atEnd() || p.atGroupEnd() {
+			return nodes, nil
+		}
+
+		node, err := p.parseScripted()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+// atGroupEnd returns true if the next token ends a group.
+func (p *parser) atGroupEnd() bool {
+	switch p.src[p.pos] {
+	case '}', '&':
+		return true
+	case '\\':
+		name := p.peekCommand()
+		return name == `\` || name == "right" || name == "end"
+	}
+	return false
+}
+
+// parseScripted parses an element along with any superscript, subscript, and
+// primes attached to it.
+func (p *parser) parseScripted() (string, error) {
+	base := atom{ml: "<mrow></mrow>"}
+	if c := p.src[p.pos]; c != '^' && c != '_' && c != '\'' {
+		var err error
+		if base, err = p.parseAtom(); err != nil {
+			return "", err
+		}
+	}
+
+	var sup, sub string
+	primes := ""
+	for {
+		p.skipSpace()
+		if p.atEnd() {
+			break
+		}
+
+		start := p.pos
+		switch p.src[p.pos] {
+		case '\'':
+			p.pos++
+			primes += "′"
+			continue
+		case '^', '_':
+		default:
+			return scripted(base, sub, sup, primes, p.display), nil
+		}
+
+		c := p.src[p.pos]
+		p.pos++
+		arg, err := p.parseArg()
+		if err != nil {
+			return "", err
+		}
+
+		if c == '^' {
+			if sup != "" {
+				return "", &Error{Offset: start, Msg: "double superscript"}
+			}
+			sup = arg
+		} else {
+			if sub != "" {
+				return "", &Error{Offset: start, Msg: "double subscript"}
+			}
+			sub = arg
+		}
+	}
+
+	return scripted(base, sub, sup, primes, p.display), nil
+}
+
+// scripted returns the MathML for base with the given scripts.
+func scripted(base atom, sub, sup, primes string, display bool) string {
+	if primes != "" {
+		prime := "<mo>" + primes + "</mo>"
+		if sup == "" {
+			sup = prime
+		} else {
+			sup = "<mrow>" + prime + sup + "</mrow>"
+		}
+	}
+
+	under := base.under || (base.limits && display)
+	switch {
+	case sub != "" && sup != "" && under:
+		return "<munderover>" + base.ml + sub + sup + "</munderover>"
+	case sub != "" && sup != "":
+		return "<msubsup>" + base.ml + sub + sup + "</msubsup>"
+	case sub != "" && under:
+		return "<munder>" + base.ml + sub + "</munder>"
+	case sub != "":
+		return "<msub>" + base.ml + sub + "</msub>"
+	case sup != "" && under:
+		return "<mover>" + base.ml + sup + "</mover>"
+	case sup != "":
+		return "<msup>" + base.ml + sup + "</msup>"
+	}
+	return base.ml
+}
+
+// parseArg parses the argument of a command or script, which is either a
+// group or a single token.
+func (p *parser) parseArg() (string, error) {
+	p.skipSpace()
+	if p.atEnd() {
+		return "", &Error{Offset: p.pos, Msg: "missing argument"}
+	}
+
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		a, err := p.parseAtom()
+		return a.ml, err
+	case c >= '0' && c <= '9':
+		// Only the first digit is an argument e.g. x^23 is x squared
+		// followed by 3.
+		p.pos++
+		return "<mn>" + string(c) + "</mn>", nil
+	case c == '}' || c == '&' || c == '^' || c == '_':
+		return "", &Error{Offset: p.pos, Msg: "missing argument"}
+	}
+
+	a, err := p.parseAtom()
+	return a.ml, err
+}
+
+// parseAtom parses a single element without any scripts.
+func (p *parser) parseAtom() (atom, error) {
+	start := p.pos
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+
+	switch {
+	case r == '{':
+		p.pos++
+		nodes, err := p.parseList()
+		if err != nil {
+			return atom{}, err
+		}
+		if p.atEnd() || p.src[p.pos] != '}' {
+			return atom{}, &Error{Offset: start, Msg: "unclosed {"}
+		}
+		p.pos++
+		return atom{ml: mrow(nodes)}, nil
+
+	case r == '\\':
+		return p.parseCommand()
+
+	case isDigit(r) || (r == '.' && p.pos+1 < len(p.src) && isDigit(rune(p.src[p.pos+1]))):
+		for p.pos < len(p.src) && (isDigit(rune(p.src[p.pos])) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		return atom{ml: "<mn>" + p.src[start:p.pos] + "</mn>"}, nil
+
+	case unicode.IsLetter(r):
+		p.pos += size
+		return atom{ml: mi(string(r))}, nil
+
+	case r == '~':
+		p.pos++
+		return atom{ml: space("0.278em")}, nil
+
+	case r == '}' || r == '&' || r == '$' || r == '#' || r == '%':
+		return atom{}, p.unexpected()
+	}
+
+	p.pos += size
+	return atom{ml: mo(string(r))}, nil
+}
+
+// parseCommand parses a command starting with a backslash.
+func (p *parser) parseCommand() (atom, error) {
+	start := p.pos
+	name := p.command()
+
+	if s, ok := identifiers[name]; ok {
+		return atom{ml: mi(s)}, nil
+	}
+	if s, ok := operators[name]; ok {
+		return atom{ml: mo(s)}, nil
+	}
+	if s, ok := largeOperators[name]; ok {
+		return atom{ml: `<mo largeop="true" movablelimits="true">` + s + "</mo>", limits: true}, nil
+	}
+	if s, ok := integrals[name]; ok {
+		return atom{ml: `<mo largeop="true">` + s + "</mo>"}, nil
+	}
+	if functions[name] {
+		return atom{ml: "<mi>" + name + "</mi>"}, nil
+	}
+	if limitFunctions[name] {
+		return atom{ml: "<mi>" + name + "</mi>", limits: true}, nil
+	}
+	if width, ok := spaces[name]; ok {
+		return atom{ml: space(width)}, nil
+	}
+	if s, ok := accents[name]; ok {
+		arg, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		return atom{ml: `<mover accent="true">` + arg + `<mo stretchy="` + fmt.Sprint(strings.HasPrefix(name, "wide") || name == "overline") + `">` + s + "</mo></mover>"}, nil
+	}
+	if variant, ok := fonts[name]; ok {
+		return p.parseFont(variant)
+	}
+	if size, ok := bigDelimiters[name]; ok {
+		delim, err := p.parseDelimiter()
+		if err != nil {
+			return atom{}, err
+		}
+		return atom{ml: `<mo fence="true" stretchy="true" minsize="` + size + `" maxsize="` + size + `">` + delim + "</mo>"}, nil
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac":
+		num, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		den, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		return atom{ml: "<mfrac>" + num + den + "</mfrac>"}, nil
+
+	case "binom":
+		top, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		bottom, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		return atom{ml: `<mrow><mo>(</mo><mfrac linethickness="0">` + top + bottom + `</mfrac><mo>)</mo></mrow>`}, nil
+
+	case "sqrt":
+		var index string
+		if p.skipSpace(); !p.atEnd() && p.src[p.pos] == '[' {
+			optStart := p.pos
+			p.pos++
+			nodes, err := p.parseUntil(']')
+			if err != nil {
+				return atom{}, err
+			}
+			if p.atEnd() {
+				return atom{}, &Error{Offset: optStart, Msg: "unclosed ["}
+			}
+			p.pos++
+			index = mrow(nodes)
+		}
+		arg, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		if index != "" {
+			return atom{ml: "<mroot>" + arg + index + "</mroot>"}, nil
+		}
+		return atom{ml: "<msqrt>" + arg + "</msqrt>"}, nil
+
+	case "underline":
+		arg, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		return atom{ml: `<munder accentunder="true">` + arg + `<mo stretchy="true">_</mo></munder>`}, nil
+
+	case "overbrace", "underbrace":
+		arg, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		if name == "overbrace" {
+			return atom{ml: `<mover>` + arg + `<mo stretchy="true">⏞</mo></mover>`, under: true}, nil
+		}
+		return atom{ml: `<munder>` + arg + `<mo stretchy="true">⏟</mo></munder>`, under: true}, nil
+
+	case "text", "textrm", "textnormal", "mbox", "textbf", "textit":
+		text, err := p.parseRawGroup()
+		if err != nil {
+			return atom{}, err
+		}
+		attr := ""
+		switch name {
+		case "textbf":
+			attr = ` mathvariant="bold"`
+		case "textit":
+			attr = ` mathvariant="italic"`
+		}
+		return atom{ml: "<mtext" + attr + ">" + html.EscapeString(text) + "</mtext>"}, nil
+
+	case "operatorname":
+		text, err := p.parseRawGroup()
+		if err != nil {
+			return atom{}, err
+		}
+		return atom{ml: "<mi>" + html.EscapeString(strings.TrimSpace(text)) + "</mi>"}, nil
+
+	case "left":
+		return p.parseFenced(start)
+
+	case "begin":
+		return p.parseEnvironment(start)
+
+	case "bmod", "mod":
+		return atom{ml: "<mo lspace=\"0.5em\" rspace=\"0.5em\">mod</mo>"}, nil
+
+	case "pmod":
+		arg, err := p.parseArg()
+		if err != nil {
+			return atom{}, err
+		}
+		return atom{ml: `<mrow><mo lspace="1em">(</mo><mo rspace="0.333em">mod</mo>` + arg + "<mo>)</mo></mrow>"}, nil
+
+	case "displaystyle", "textstyle":
+		return atom{ml: "<mrow></mrow>"}, nil
+
+	case "right", "end", `\`:
+		return atom{}, &Error{Offset: start, Msg: fmt.Sprintf(`unexpected \%s`, name)}
+
+	case "":
+		return atom{}, &Error{Offset: start, Msg: "incomplete command"}
+	}
+
+	if len(name) == 1 {
+		switch name {
+		case "{", "}", "|", "%", "$", "&", "#", "_", "/":
+			if name == "|" {
+				name = "‖"
+			}
+			return atom{ml: mo(name)}, nil
+		}
+	}
+
+	return atom{}, &Error{Offset: start, Msg: fmt.Sprintf(`unknown command \%s`, name)}
+}
+
+// parseFont parses the argument of a font command such as \mathbf. Arguments
+// made up of only letters are a single identifier, so \mathrm{d} is an upright
+// d and \mathrm{max} is a single name.
+func (p *parser) parseFont(variant string) (atom, error) {
+	p.skipSpace()
+	if !p.atEnd() && p.src[p.pos] == '{' {
+		if end := strings.IndexByte(p.src[p.pos:], '}'); end > 0 {
+			text := p.src[p.pos+1 : p.pos+end]
+			if text != "" && strings.IndexFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) < 0 {
+				p.pos += end + 1
+				return atom{ml: `<mi mathvariant="` + variant + `">` + html.EscapeString(text) + "</mi>"}, nil
+			}
+		}
+	}
+
+	arg, err := p.parseArg()
+	if err != nil {
+		return atom{}, err
+	}
+	return atom{ml: strings.ReplaceAll(arg, "<mi>", `<mi mathvariant="`+variant+`">`)}, nil
+}
+
+// parseFenced parses the contents of \left ... \right, starting after \left.
+func (p *parser) parseFenced(start int) (atom, error) {
+	open, err := p.parseDelimiter()
+	if err != nil {
+		return atom{}, err
+	}
+
+	nodes, err := p.parseList()
+	if err != nil {
+		return atom{}, err
+	}
+	if p.atEnd() || p.peekCommand() != "right" {
+		return atom{}, &Error{Offset: start, Msg: `\left without matching \right`}
+	}
+	p.command()
+
+	closing, err := p.parseDelimiter()
+	if err != nil {
+		return atom{}, err
+	}
+
+	return atom{ml: "<mrow>" + fence(open) + strings.Join(nodes, "") + fence(closing) + "</mrow>"}, nil
+}
+
+// parseDelimiter parses the delimiter following \left, \right, or a sizing
+// command such as \big. The delimiter "." is returned as an empty string.
+func (p *parser) parseDelimiter() (string, error) {
+	p.skipSpace()
+	if p.atEnd() {
+		return "", &Error{Offset: p.pos, Msg: "missing delimiter"}
+	}
+
+	start := p.pos
+	if p.src[p.pos] == '\\' {
+		name := p.command()
+		if s, ok := delimiters[name]; ok {
+			return s, nil
+		}
+		return "", &Error{Offset: start, Msg: fmt.Sprintf(`invalid delimiter \%s`, name)}
+	}
+
+	c := p.src[p.pos]
+	p.pos++
+	switch c {
+	case '.':
+		return "", nil
+	case '(', ')', '[', ']', '|', '/', '<', '>':
+		if c == '<' {
+			return "⟨", nil
+		}
+		if c == '>' {
+			return "⟩", nil
+		}
+		return string(c), nil
+	}
+	return "", &Error{Offset: start, Msg: fmt.Sprintf("invalid delimiter %q", c)}
+}
+
+// parseEnvironment parses a \begin{name} ... \end{name} environment, starting
+// after \begin.
+func (p *parser) parseEnvironment(start int) (atom, error) {
+	name, err := p.parseRawGroup()
+	if err != nil {
+		return atom{}, err
+	}
+
+	env, ok := environments[name]
+	if !ok {
+		return atom{}, &Error{Offset: start, Msg: fmt.Sprintf("unknown environment %s", name)}
+	}
+
+	if name == "array" {
+		// The column specification only affects alignment, which is left to
+		// the default.
+		if _, err := p.parseRawGroup(); err != nil {
+			return atom{}, err
+		}
+	}
+
+	var rows [][]string
+	var cells []string
+	for {
+		nodes, err := p.parseList()
+		if err != nil {
+			return atom{}, err
+		}
+		cells = append(cells, mrow(nodes))
+
+		if p.atEnd() {
+			return atom{}, &Error{Offset: start, Msg: fmt.Sprintf(`\begin{%s} without matching \end`, name)}
+		}
+
+		if p.src[p.pos] == '&' {
+			p.pos++
+			continue
+		}
+
+		endStart := p.pos
+		cmd := p.peekCommand()
+		if cmd == `\` {
+			p.command()
+			rows = append(rows, cells)
+			cells = nil
+			continue
+		}
+		if cmd != "end" {
+			return atom{}, p.unexpected()
+		}
+
+		p.command()
+		endName, err := p.parseRawGroup()
+		if err != nil {
+			return atom{}, err
+		}
+		if endName != name {
+			return atom{}, &Error{Offset: endStart, Msg: fmt.Sprintf(`\begin{%s} ended by \end{%s}`, name, endName)}
+		}
+		// A trailing \\ doesn't start a new row.
+		if len(cells) > 1 || cells[0] != "<mrow></mrow>" {
+			rows = append(rows, cells)
+		}
+		break
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<mtable")
+	if env.align != "" {
+		sb.WriteString(` columnalign="` + env.align + `"`)
+	}
+	sb.WriteString(">")
+	for _, row := range rows {
+		sb.WriteString("<mtr>")
+		for _, cell := range row {
+			sb.WriteString("<mtd>" + cell + "</mtd>")
+		}
+		sb.WriteString("</mtr>")
+	}
+	sb.WriteString("</mtable>")
+
+	if env.open == "" && env.close == "" {
+		return atom{ml: sb.String()}, nil
+	}
+	return atom{ml: "<mrow>" + fence(env.open) + sb.String() + fence(env.close) + "</mrow>"}, nil
+}
+
+// parseUntil parses elements until the given closing character, which is not
+// consumed.
+func (p *parser) parseUntil(c byte) ([]string, error) {
+	var nodes []string
+	for {
+		p.skipSpace()
+		if p.atEnd() || p.src[p.pos] == c || p.atGroupEnd() {
+			return nodes, nil
+		}
+
+		node, err := p.parseScripted()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+// parseRawGroup parses a group and returns its contents without interpreting
+// them, as for \text.
+func (p *parser) parseRawGroup() (string, error) {
+	p.skipSpace()
+	if p.atEnd() || p.src[p.pos] != '{' {
+		return "", &Error{Offset: p.pos, Msg: "missing {"}
+	}
+
+	start := p.pos
+	depth := 0
+	for i := p.pos; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos = i + 1
+				return p.src[start+1 : i], nil
+			}
+		}
+	}
+
+	return "", &Error{Offset: start, Msg: "unclosed {"}
+}
+
+// command consumes a command and returns its name without the backslash. A
+// command is either a run of letters or a single other character.
+func (p *parser) command() string {
+	name := p.peekCommand()
+	p.pos += 1 + len(name)
+	return name
+}
+
+// peekCommand returns the name of the command at the current position without
+// consuming it.
+func (p *parser) peekCommand() string {
+	if p.atEnd() || p.src[p.pos] != '\\' {
+		return ""
+	}
+
+	end := p.pos + 1
+	for end < len(p.src) && isLetter(p.src[end]) {
+		end++
+	}
+	if end == p.pos+1 && end < len(p.src) {
+		_, size := utf8.DecodeRuneInString(p.src[end:])
+		end += size
+	}
+	return p.src[p.pos+1 : end]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.src)
+}
+
+// unexpected returns an error for the token at the current position.
+func (p *parser) unexpected() error {
+	switch c := p.src[p.pos]; c {
+	case '}':
+		return &Error{Offset: p.pos, Msg: "unexpected }"}
+	case '\\':
+		return &Error{Offset: p.pos, Msg: fmt.Sprintf(`unexpected \%s`, p.peekCommand())}
+	default:
+		return &Error{Offset: p.pos, Msg: fmt.Sprintf("unexpected %c", c)}
+	}
+}
+
+func mrow(nodes []string) string {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	return "<mrow>" + strings.Join(nodes, "") + "</mrow>"
+}
+
+// mi returns an identifier. Single uppercase Greek letters are upright, as in
+// LaTeX.
+func mi(s string) string {
+	if r, _ := utf8.DecodeRuneInString(s); unicode.Is(unicode.Greek, r) && unicode.IsUpper(r) {
+		return `<mi mathvariant="normal">` + s + "</mi>"
+	}
+	return "<mi>" + html.EscapeString(s) + "</mi>"
+}
+
+func mo(s string) string {
+	return "<mo>" + html.EscapeString(s) + "</mo>"
+}
+
+// fence returns a stretchy delimiter, or nothing for an empty delimiter.
+func fence(s string) string {
+	if s == "" {
+		return ""
+	}
+	return `<mo fence="true" stretchy="true">` + html.EscapeString(s) + "</mo>"
+}
+
+func space(width string) string {
+	return `<mspace width="` + width + `"></mspace>`
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,108 @@
+package mathml_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/mathml"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		tex     string
+		display bool
+		want    string
+	}{
+		{
+			name: "scripts",
+			tex:  `x^2 + y_1`,
+			want: `<mrow><msup><mi>x</mi><mn>2</mn></msup><mo>+</mo><msub><mi>y</mi><mn>1</mn></msub></mrow>`,
+		},
+		{
+			name: "fraction",
+			tex:  `\frac{a+1}{2}`,
+			want: `<mfrac><mrow><mi>a</mi><mo>+</mo><mn>1</mn></mrow><mn>2</mn></mfrac>`,
+		},
+		{
+			name: "root",
+			tex:  `\sqrt[3]{x}`,
+			want: `<mroot><mi>x</mi><mn>3</mn></mroot>`,
+		},
+		{
+			name: "inline limits",
+			tex:  `\sum_{i=1}^n i`,
+			want: `<mrow><msubsup><mo largeop="true" movablelimits="true">∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></msubsup><mi>i</mi></mrow>`,
+		},
+		{
+			name:    "display limits",
+			tex:     `\lim_{x \to 0} f`,
+			display: true,
+			want:    `<mrow><munder><mi>lim</mi><mrow><mi>x</mi><mo>→</mo><mn>0</mn></mrow></munder><mi>f</mi></mrow>`,
+		},
+		{
+			name: "fence",
+			tex:  `\left( \alpha \right]`,
+			want: `<mrow><mo fence="true" stretchy="true">(</mo><mi>α</mi><mo fence="true" stretchy="true">]</mo></mrow>`,
+		},
+		{
+			name: "matrix",
+			tex:  `\begin{pmatrix} 1 & 0 \\ 0 & 1 \end{pmatrix}`,
+			want: `<mrow><mo fence="true" stretchy="true">(</mo><mtable><mtr><mtd><mn>1</mn></mtd><mtd><mn>0</mn></mtd></mtr><mtr><mtd><mn>0</mn></mtd><mtd><mn>1</mn></mtd></mtr></mtable><mo fence="true" stretchy="true">)</mo></mrow>`,
+		},
+		{
+			name: "fonts and text",
+			tex:  `x \in \mathbb{R} \text{ if } \Gamma`,
+			want: `<mrow><mi>x</mi><mo>∈</mo><mi mathvariant="double-struck">R</mi><mtext> if </mtext><mi mathvariant="normal">Γ</mi></mrow>`,
+		},
+		{
+			name: "prime and escaping",
+			tex:  `f'(x) < 1`,
+			want: `<mrow><msup><mi>f</mi><mo>′</mo></msup><mo>(</mo><mi>x</mi><mo>)</mo><mo>&lt;</mo><mn>1</mn></mrow>`,
+		},
+		{
+			name:    "lines",
+			tex:     `a \\ b`,
+			display: true,
+			want:    `<mtable><mtr><mtd><mi>a</mi></mtd></mtr><mtr><mtd><mi>b</mi></mtd></mtr></mtable>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := mathml.Convert(tc.tex, tc.display)
+			assert.OK(t, err).Fatal()
+
+			prefix := `<math xmlns="http://www.w3.org/1998/Math/MathML"><semantics>`
+			if tc.display {
+				prefix = `<math xmlns="http://www.w3.org/1998/Math/MathML" display="block"><semantics>`
+			}
+			assert.True(t, "prefix", strings.HasPrefix(got, prefix))
+			assert.True(t, "annotation", strings.HasSuffix(got, "</annotation></semantics></math>"))
+
+			body := strings.TrimPrefix(got, prefix)
+			body = body[:strings.Index(body, "<annotation")]
+			assert.Equal(t, "mathml", tc.want, body)
+		})
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	testCases := map[string]string{
+		`\frac{1}`:         "missing argument at offset 8",
+		`x^2^3`:            "double superscript at offset 3",
+		`\foo + 1`:         `unknown command \foo at offset 0`,
+		`{x`:               "unclosed { at offset 0",
+		`x}`:               "unexpected } at offset 1",
+		`\left( x`:         `\left without matching \right at offset 0`,
+		`\begin{matrix} x`: `\begin{matrix} without matching \end at offset 0`,
+	}
+
+	for tex, want := range testCases {
+		t.Run(tex, func(t *testing.T) {
+			_, err := mathml.Convert(tex, false)
+			assert.Error(t, err, want)
+		})
+	}
+}
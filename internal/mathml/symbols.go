@@ -0,0 +1,302 @@
+package mathml
+
+// identifiers maps commands to the characters of identifiers.
+var identifiers = map[string]string{
+	"alpha":      "α",
+	"beta":       "β",
+	"gamma":      "γ",
+	"delta":      "δ",
+	"epsilon":    "ϵ",
+	"varepsilon": "ε",
+	"zeta":       "ζ",
+	"eta":        "η",
+	"theta":      "θ",
+	"vartheta":   "ϑ",
+	"iota":       "ι",
+	"kappa":      "κ",
+	"lambda":     "λ",
+	"mu":         "μ",
+	"nu":         "ν",
+	"xi":         "ξ",
+	"omicron":    "ο",
+	"pi":         "π",
+	"varpi":      "ϖ",
+	"rho":        "ρ",
+	"varrho":     "ϱ",
+	"sigma":      "σ",
+	"varsigma":   "ς",
+	"tau":        "τ",
+	"upsilon":    "υ",
+	"phi":        "ϕ",
+	"varphi":     "φ",
+	"chi":        "χ",
+	"psi":        "ψ",
+	"omega":      "ω",
+	"Gamma":      "Γ",
+	"Delta":      "Δ",
+	"Theta":      "Θ",
+	"Lambda":     "Λ",
+	"Xi":         "Ξ",
+	"Pi":         "Π",
+	"Sigma":      "Σ",
+	"Upsilon":    "Υ",
+	"Phi":        "Φ",
+	"Psi":        "Ψ",
+	"Omega":      "Ω",
+	"infty":      "∞",
+	"partial":    "∂",
+	"nabla":      "∇",
+	"emptyset":   "∅",
+	"varnothing": "∅",
+	"ell":        "ℓ",
+	"hbar":       "ℏ",
+	"Re":         "ℜ",
+	"Im":         "ℑ",
+	"aleph":      "ℵ",
+	"imath":      "ı",
+	"jmath":      "ȷ",
+}
+
+// operators maps commands to the characters of operators, relations, and
+// punctuation.
+var operators = map[string]string{
+	"times":           "×",
+	"div":             "÷",
+	"cdot":            "⋅",
+	"pm":              "±",
+	"mp":              "∓",
+	"ast":             "∗",
+	"star":            "⋆",
+	"circ":            "∘",
+	"bullet":          "∙",
+	"oplus":           "⊕",
+	"ominus":          "⊖",
+	"otimes":          "⊗",
+	"odot":            "⊙",
+	"leq":             "≤",
+	"le":              "≤",
+	"geq":             "≥",
+	"ge":              "≥",
+	"neq":             "≠",
+	"ne":              "≠",
+	"approx":          "≈",
+	"equiv":           "≡",
+	"sim":             "∼",
+	"simeq":           "≃",
+	"cong":            "≅",
+	"propto":          "∝",
+	"ll":              "≪",
+	"gg":              "≫",
+	"prec":            "≺",
+	"succ":            "≻",
+	"in":              "∈",
+	"notin":           "∉",
+	"ni":              "∋",
+	"subset":          "⊂",
+	"supset":          "⊃",
+	"subseteq":        "⊆",
+	"supseteq":        "⊇",
+	"cup":             "∪",
+	"cap":             "∩",
+	"setminus":        "∖",
+	"land":            "∧",
+	"wedge":           "∧",
+	"lor":             "∨",
+	"vee":             "∨",
+	"lnot":            "¬",
+	"neg":             "¬",
+	"to":              "→",
+	"rightarrow":      "→",
+	"leftarrow":       "←",
+	"gets":            "←",
+	"leftrightarrow":  "↔",
+	"Rightarrow":      "⇒",
+	"Leftarrow":       "⇐",
+	"Leftrightarrow":  "⇔",
+	"longrightarrow":  "⟶",
+	"longleftarrow":   "⟵",
+	"implies":         "⟹",
+	"impliedby":       "⟸",
+	"iff":             "⟺",
+	"mapsto":          "↦",
+	"uparrow":         "↑",
+	"downarrow":       "↓",
+	"mid":             "∣",
+	"parallel":        "∥",
+	"perp":            "⊥",
+	"forall":          "∀",
+	"exists":          "∃",
+	"nexists":         "∄",
+	"ldots":           "…",
+	"dots":            "…",
+	"cdots":           "⋯",
+	"vdots":           "⋮",
+	"ddots":           "⋱",
+	"colon":           ":",
+	"prime":           "′",
+	"angle":           "∠",
+	"triangle":        "△",
+	"therefore":       "∴",
+	"because":         "∵",
+	"dagger":          "†",
+	"vdash":           "⊢",
+	"models":          "⊨",
+	"top":             "⊤",
+	"bot":             "⊥",
+	"langle":          "⟨",
+	"rangle":          "⟩",
+	"lfloor":          "⌊",
+	"rfloor":          "⌋",
+	"lceil":           "⌈",
+	"rceil":           "⌉",
+	"lvert":           "|",
+	"rvert":           "|",
+	"vert":            "|",
+	"Vert":            "‖",
+	"lVert":           "‖",
+	"rVert":           "‖",
+	"backslash":       "\\",
+	"lbrace":          "{",
+	"rbrace":          "}",
+	"hookrightarrow":  "↪",
+	"rightleftarrows": "⇄",
+}
+
+// largeOperators maps commands to large operators whose limits are placed
+// above and below them in display mode.
+var largeOperators = map[string]string{
+	"sum":       "∑",
+	"prod":      "∏",
+	"coprod":    "∐",
+	"bigcup":    "⋃",
+	"bigcap":    "⋂",
+	"bigoplus":  "⨁",
+	"bigotimes": "⨂",
+	"bigvee":    "⋁",
+	"bigwedge":  "⋀",
+}
+
+// integrals maps commands to large operators whose limits are always placed to
+// the side.
+var integrals = map[string]string{
+	"int":   "∫",
+	"iint":  "∬",
+	"iiint": "∭",
+	"oint":  "∮",
+}
+
+// functions are named functions that are written upright.
+var functions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+	"arcsin": true, "arccos": true, "arctan": true,
+	"sinh": true, "cosh": true, "tanh": true, "coth": true,
+	"log": true, "ln": true, "lg": true, "exp": true,
+	"deg": true, "dim": true, "arg": true, "ker": true, "hom": true,
+}
+
+// limitFunctions are named functions whose limits are placed below them in
+// display mode.
+var limitFunctions = map[string]bool{
+	"lim": true, "liminf": true, "limsup": true,
+	"max": true, "min": true, "sup": true, "inf": true,
+	"det": true, "gcd": true, "Pr": true,
+}
+
+// spaces maps spacing commands to their widths.
+var spaces = map[string]string{
+	",":         "0.167em",
+	"thinspace": "0.167em",
+	":":         "0.222em",
+	">":         "0.222em",
+	";":         "0.278em",
+	" ":         "0.278em",
+	"quad":      "1em",
+	"qquad":     "2em",
+	"!":         "-0.167em",
+}
+
+// accents maps accent commands to the characters placed over their argument.
+var accents = map[string]string{
+	"hat":       "^",
+	"widehat":   "^",
+	"bar":       "¯",
+	"overline":  "¯",
+	"vec":       "→",
+	"dot":       "˙",
+	"ddot":      "¨",
+	"tilde":     "˜",
+	"widetilde": "˜",
+	"check":     "ˇ",
+	"breve":     "˘",
+	"acute":     "´",
+	"grave":     "`",
+}
+
+// fonts maps font commands to MathML math variants.
+var fonts = map[string]string{
+	"mathrm":     "normal",
+	"mathit":     "italic",
+	"mathbf":     "bold",
+	"boldsymbol": "bold-italic",
+	"mathbb":     "double-struck",
+	"mathcal":    "script",
+	"mathscr":    "script",
+	"mathfrak":   "fraktur",
+	"mathsf":     "sans-serif",
+	"mathtt":     "monospace",
+}
+
+// bigDelimiters maps sizing commands to the size of the delimiter following
+// them.
+var bigDelimiters = map[string]string{
+	"big": "1.2em", "bigl": "1.2em", "bigr": "1.2em", "bigm": "1.2em",
+	"Big": "1.8em", "Bigl": "1.8em", "Bigr": "1.8em", "Bigm": "1.8em",
+	"bigg": "2.4em", "biggl": "2.4em", "biggr": "2.4em", "biggm": "2.4em",
+	"Bigg": "3em", "Biggl": "3em", "Biggr": "3em", "Biggm": "3em",
+}
+
+// delimiters maps commands that can follow \left and \right to their
+// characters.
+var delimiters = map[string]string{
+	"{":      "{",
+	"}":      "}",
+	"|":      "‖",
+	"lbrace": "{",
+	"rbrace": "}",
+	"langle": "⟨",
+	"rangle": "⟩",
+	"lfloor": "⌊",
+	"rfloor": "⌋",
+	"lceil":  "⌈",
+	"rceil":  "⌉",
+	"vert":   "|",
+	"lvert":  "|",
+	"rvert":  "|",
+	"Vert":   "‖",
+	"lVert":  "‖",
+	"rVert":  "‖",
+}
+
+// environment describes how a \begin ... \end environment is rendered.
+type environment struct {
+	// The delimiters around the table, if any.
+	open, close string
+
+	// The alignment of the columns, if not centered.
+	align string
+}
+
+var environments = map[string]environment{
+	"matrix":   {},
+	"array":    {},
+	"pmatrix":  {open: "(", close: ")"},
+	"bmatrix":  {open: "[", close: "]"},
+	"Bmatrix":  {open: "{", close: "}"},
+	"vmatrix":  {open: "|", close: "|"},
+	"Vmatrix":  {open: "‖", close: "‖"},
+	"cases":    {open: "{", align: "left"},
+	"aligned":  {align: "right left"},
+	"align":    {align: "right left"},
+	"align*":   {align: "right left"},
+	"gathered": {},
+}
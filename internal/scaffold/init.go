@@ -0,0 +1,172 @@
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/haleyrc/stele/internal/site"
+)
+
+// Ignored lists the entries written to a new site's .gitignore.
+var Ignored = []string{"dist/", ".stele/"}
+
+// SiteOptions contains the settings for a new site.
+type SiteOptions struct {
+	// The site configuration to write to stele.yaml.
+	Config site.SiteConfig
+
+	// Whether to create a placeholder about.md.
+	About bool
+}
+
+// siteConfig mirrors the required fields of site.SiteConfig in the order they
+// should appear in a new stele.yaml.
+type siteConfig struct {
+	Title       string `yaml:"title"`
+	Author      string `yaml:"author"`
+	Description string `yaml:"description"`
+	BaseURL     string `yaml:"baseURL"`
+}
+
+const samplePostBody = `Welcome to your new site! This post is a draft, so it only shows up when
+running ` + "`stele dev`" + `. When you're ready to publish it, remove the draft flag and
+add a date to the frontmatter.
+`
+
+const aboutBody = `# About
+
+Tell your readers a little bit about yourself.
+`
+
+// NewSite creates a new site in dir, which is created if it does not exist,
+// and returns the paths of the files that were written. The directory must not
+// already contain a stele.yaml. If a .gitignore already exists, any missing
+// entries are appended to it.
+//
+// If any file can't be written, the files and directories created so far are
+// removed again so that a partial site isn't left behind.
+func NewSite(dir string, opts SiteOptions) ([]string, error) {
+	if err := opts.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("new site: %w", err)
+	}
+
+	cfg := siteConfig{
+		Title:       opts.Config.Title,
+		Author:      opts.Config.Author,
+		Description: opts.Config.Description,
+		BaseURL:     opts.Config.BaseURL,
+	}
+	cfgBytes, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("new site: %w", err)
+	}
+
+	// Everything created is recorded in order so that it can be removed in
+	// reverse if a later step fails. The posts directory is created along
+	// with the sample post.
+	created := missingPaths(filepath.Join(dir, "posts"))
+	fail := func(err error) ([]string, error) {
+		for i := len(created) - 1; i >= 0; i-- {
+			_ = os.Remove(created[i]) // #nosec G104 - Cleanup is best effort, the original error is returned
+		}
+		return nil, fmt.Errorf("new site: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fail(err)
+	}
+
+	var paths []string
+
+	configPath := filepath.Join(dir, "stele.yaml")
+	if err := writeNew(configPath, cfgBytes); err != nil {
+		return fail(err)
+	}
+	paths = append(paths, configPath)
+	created = append(created, configPath)
+
+	fm := postFrontmatter{
+		Title:       "Hello, World",
+		Description: "The first post on my new site, created by stele init.",
+		Tags:        []string{},
+		Draft:       true,
+	}
+	postPath, err := createPost(dir, "", fm, samplePostBody)
+	if err != nil {
+		return fail(err)
+	}
+	paths = append(paths, postPath)
+	created = append(created, postPath)
+
+	if opts.About {
+		aboutPath := filepath.Join(dir, "about.md")
+		if err := writeNew(aboutPath, []byte(aboutBody)); err != nil {
+			return fail(err)
+		}
+		paths = append(paths, aboutPath)
+		created = append(created, aboutPath)
+	}
+
+	ignorePath := filepath.Join(dir, ".gitignore")
+	created = append(created, missingPaths(ignorePath)...)
+	if err := updateGitignore(ignorePath); err != nil {
+		return fail(err)
+	}
+	paths = append(paths, ignorePath)
+
+	return paths, nil
+}
+
+// missingPaths returns path and each of its parents that don't exist yet,
+// outermost first.
+func missingPaths(path string) []string {
+	var missing []string
+	for {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		missing = append([]string{path}, missing...)
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	return missing
+}
+
+// updateGitignore appends any entries from Ignored that are missing from the
+// file at path, creating it if necessary.
+func updateGitignore(path string) error {
+	existing, err := os.ReadFile(path) // #nosec G304 - File lives in the user's site directory
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	present := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(existing))
+	for scanner.Scan() {
+		present[strings.TrimSpace(scanner.Text())] = true
+	}
+
+	var buf bytes.Buffer
+	buf.Write(existing)
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	for _, entry := range Ignored {
+		if !present[entry] {
+			buf.WriteString(entry + "\n")
+		}
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0644) // #nosec G306 - .gitignore is meant to be readable
+}
@@ -0,0 +1,188 @@
+// Package scaffold creates new content files with valid starter frontmatter.
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/haleyrc/stele/internal/textutil"
+)
+
+// DescriptionPlaceholder is the description given to new posts. Posts must
+// have a description, so this stands in until the author writes one.
+const DescriptionPlaceholder = "TODO: Describe this post."
+
+// postFrontmatter mirrors site.PostFrontmatter with the fields in the order
+// they should appear in a new file. Drafts must not have a date, so there is
+// no date field.
+type postFrontmatter struct {
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Tags        []string `yaml:"tags,flow"`
+	Draft       bool     `yaml:"draft"`
+}
+
+// noteFrontmatter mirrors site.NoteFrontmatter with the fields in the order
+// they should appear in a new file.
+type noteFrontmatter struct {
+	Title  string   `yaml:"title"`
+	Tags   []string `yaml:"tags,flow"`
+	Pinned bool     `yaml:"pinned"`
+}
+
+// seriesMetadata mirrors site.SeriesMetadata.
+type seriesMetadata struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// NewPost creates a draft post for title in the posts directory of the site
+// rooted at dir and returns its path. If series is not empty, the post is
+// created in that series, which must already exist.
+func NewPost(dir, title, series string) (string, error) {
+	fm := postFrontmatter{
+		Title:       title,
+		Description: DescriptionPlaceholder,
+		Tags:        []string{},
+		Draft:       true,
+	}
+
+	path, err := createPost(dir, series, fm, "")
+	if err != nil {
+		return "", fmt.Errorf("new post: %w", err)
+	}
+
+	return path, nil
+}
+
+func createPost(dir, series string, fm postFrontmatter, body string) (string, error) {
+	slug, err := slugify(fm.Title)
+	if err != nil {
+		return "", err
+	}
+
+	postsDir := filepath.Join(dir, "posts")
+	if series != "" {
+		postsDir = filepath.Join(postsDir, series)
+		if _, err := os.Stat(filepath.Join(postsDir, "index.yaml")); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("series %q does not exist", series)
+			}
+			return "", err
+		}
+	}
+
+	path := filepath.Join(postsDir, slug+".md")
+	if err := writeMarkdown(path, fm, body); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// NewSeries creates the directory and index.yaml for a series with the given
+// slug and display name in the site rooted at dir and returns the path to the
+// index file.
+func NewSeries(dir, slug, name string) (string, error) {
+	if slug != textutil.Slugify(slug) {
+		return "", fmt.Errorf("new series: invalid slug %q: try %q", slug, textutil.Slugify(slug))
+	}
+	if name == "" {
+		return "", fmt.Errorf("new series: series must have a name")
+	}
+
+	seriesDir := filepath.Join(dir, "posts", slug)
+	if err := os.MkdirAll(seriesDir, 0750); err != nil {
+		return "", fmt.Errorf("new series: %w", err)
+	}
+
+	bytes, err := yaml.Marshal(seriesMetadata{Name: name})
+	if err != nil {
+		return "", fmt.Errorf("new series: %w", err)
+	}
+
+	path := filepath.Join(seriesDir, "index.yaml")
+	if err := writeNew(path, bytes); err != nil {
+		return "", fmt.Errorf("new series: %w", err)
+	}
+
+	return path, nil
+}
+
+// NewNote creates a note for title in the notes directory of the site rooted
+// at dir and returns its path.
+func NewNote(dir, title string) (string, error) {
+	slug, err := slugify(title)
+	if err != nil {
+		return "", fmt.Errorf("new note: %w", err)
+	}
+
+	fm := noteFrontmatter{
+		Title: title,
+		Tags:  []string{},
+	}
+
+	path := filepath.Join(dir, "notes", slug+".md")
+	if err := writeMarkdown(path, fm, ""); err != nil {
+		return "", fmt.Errorf("new note: %w", err)
+	}
+
+	return path, nil
+}
+
+func slugify(title string) (string, error) {
+	if title == "" {
+		return "", fmt.Errorf("a title is required")
+	}
+
+	slug := textutil.Slugify(title)
+	if slug == "" {
+		return "", fmt.Errorf("title %q does not contain any characters usable in a slug", title)
+	}
+
+	return slug, nil
+}
+
+// writeMarkdown writes a markdown file at path containing the given
+// frontmatter and body, creating the parent directory if necessary.
+func writeMarkdown(path string, fm any, body string) error {
+	yamlBytes, err := yaml.Marshal(fm)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(yamlBytes)
+	buf.WriteString("---\n\n")
+	buf.WriteString(body)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	return writeNew(path, buf.Bytes())
+}
+
+// writeNew writes contents to path, failing if the file already exists.
+func writeNew(path string, contents []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644) // #nosec G302 G304 - Content files are meant to be readable and live in the user's site directory
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%s already exists", path)
+		}
+		return err
+	}
+
+	if _, err := f.Write(contents); err != nil {
+		_ = f.Close() // #nosec G104 - Write error takes precedence
+		return err
+	}
+
+	return f.Close()
+}
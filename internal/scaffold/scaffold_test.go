@@ -0,0 +1,172 @@
+package scaffold_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/scaffold"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+func TestNewPost(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := scaffold.NewPost(dir, "Hello, World!", "")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "path", filepath.Join(dir, "posts", "hello-world.md"), path)
+
+	post, err := site.LoadPost(path)
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "title", "Hello, World!", post.Frontmatter.Title)
+	assert.Equal(t, "description", scaffold.DescriptionPlaceholder, post.Frontmatter.Description)
+	assert.True(t, "draft", post.Frontmatter.Draft)
+}
+
+func TestNewPost_RefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := scaffold.NewPost(dir, "Hello", "")
+	assert.OK(t, err).Fatal()
+
+	_, err = scaffold.NewPost(dir, "hello", "")
+	assert.Error(t, err, "already exists")
+}
+
+func TestNewPost_InSeries(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := scaffold.NewPost(dir, "Variables", "go-basics")
+	assert.Error(t, err, "does not exist").Fatal()
+
+	_, err = scaffold.NewSeries(dir, "go-basics", "Go Basics")
+	assert.OK(t, err).Fatal()
+
+	path, err := scaffold.NewPost(dir, "Variables", "go-basics")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "path", filepath.Join(dir, "posts", "go-basics", "variables.md"), path)
+
+	series, err := site.LoadSeries(filepath.Join(dir, "posts", "go-basics"), true)
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "name", "Go Basics", series.Metadata.Name)
+	assert.Equal(t, "post count", 1, len(series.Posts))
+	assert.Equal(t, "post slug", "go-basics/variables", series.Posts[0].Slug)
+}
+
+func TestNewSeries_InvalidSlug(t *testing.T) {
+	_, err := scaffold.NewSeries(t.TempDir(), "Go Basics", "Go Basics")
+	assert.Error(t, err, "invalid slug")
+}
+
+func TestNewNote(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := scaffold.NewNote(dir, "Vim Shortcuts")
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "path", filepath.Join(dir, "notes", "vim-shortcuts.md"), path)
+
+	note, err := site.LoadNote(path)
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "title", "Vim Shortcuts", note.Frontmatter.Title)
+	assert.Equal(t, "tag count", 0, len(note.Frontmatter.Tags))
+}
+
+func TestNewNote_RequiresSlug(t *testing.T) {
+	_, err := scaffold.NewNote(t.TempDir(), "!!!")
+	assert.Error(t, err, "slug")
+}
+
+func TestNewSite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "blog")
+
+	opts := scaffold.SiteOptions{
+		Config: site.SiteConfig{
+			Title:       "My Blog",
+			Author:      "Jane Doe",
+			Description: "Thoughts and things",
+			BaseURL:     "https://example.com",
+		},
+		About: true,
+	}
+
+	paths, err := scaffold.NewSite(dir, opts)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "file count", 4, len(paths))
+
+	s, err := site.New(dir, site.SiteOptions{IncludeDrafts: true})
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "title", "My Blog", s.Config.Title)
+	assert.Equal(t, "post count", 1, len(s.Posts))
+	assert.True(t, "has about", s.About != nil)
+
+	ignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, ".gitignore", "dist/\n.stele/\n", string(ignore))
+
+	_, err = scaffold.NewSite(dir, opts)
+	assert.Error(t, err, "already exists")
+}
+
+func TestNewSite_AppendsToGitignore(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules/\ndist/"), 0600)
+	assert.OK(t, err).Fatal()
+
+	_, err = scaffold.NewSite(dir, scaffold.SiteOptions{
+		Config: site.SiteConfig{
+			Title:       "My Blog",
+			Author:      "Jane Doe",
+			Description: "Thoughts and things",
+			BaseURL:     "https://example.com",
+		},
+	})
+	assert.OK(t, err).Fatal()
+
+	ignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, ".gitignore", "node_modules/\ndist/\n.stele/\n", string(ignore))
+}
+
+func TestNewSite_RemovesPartialSite(t *testing.T) {
+	dir := t.TempDir()
+	aboutPath := filepath.Join(dir, "about.md")
+	err := os.WriteFile(aboutPath, []byte("# Me\n"), 0600)
+	assert.OK(t, err).Fatal()
+
+	_, err = scaffold.NewSite(dir, scaffold.SiteOptions{
+		Config: site.SiteConfig{
+			Title:       "My Blog",
+			Author:      "Jane Doe",
+			Description: "Thoughts and things",
+			BaseURL:     "https://example.com",
+		},
+		About: true,
+	})
+	assert.Error(t, err, "already exists")
+
+	entries, err := os.ReadDir(dir)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "entries left", 1, len(entries))
+	assert.Equal(t, "entry left", "about.md", entries[0].Name())
+
+	about, err := os.ReadFile(aboutPath)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "about.md", "# Me\n", string(about))
+}
+
+func TestNewSite_InvalidConfig(t *testing.T) {
+	_, err := scaffold.NewSite(t.TempDir(), scaffold.SiteOptions{
+		Config: site.SiteConfig{
+			Title:       "My Blog",
+			Author:      "Jane Doe",
+			Description: "Thoughts and things",
+			BaseURL:     "example.com",
+		},
+	})
+	assert.Error(t, err, "base URL")
+}
@@ -0,0 +1,279 @@
+// Package search provides full-text search over site content.
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MinTermLength is the minimum length, in characters, of an indexed term.
+// Shorter words are too common to be useful and would bloat an index.
+const MinTermLength = 2
+
+// snippetContext is the number of words shown on either side of the first
+// match in a snippet.
+const snippetContext = 12
+
+// Document is a searchable page.
+type Document struct {
+	// The type of the page, e.g. "post" or "note".
+	Type string
+
+	// The title of the page.
+	Title string
+
+	// The URL of the page.
+	URL string
+
+	// Whether the page is a draft.
+	Draft bool
+
+	// Whether the page is left out of the site being served, e.g. drafts
+	// when previewing a production build.
+	Hidden bool
+
+	// The plain text content of the page.
+	Text string
+}
+
+// Result is a document matching a search query.
+type Result struct {
+	Document
+
+	// An HTML excerpt of the document text around the first match, with
+	// matching words wrapped in mark elements.
+	Snippet string
+}
+
+// Index is an in-memory inverted index of documents.
+type Index struct {
+	docs []Document
+
+	// The terms in each document's title.
+	titles [][]string
+
+	// Each term in the document text, mapped to the documents containing it.
+	terms map[string][]posting
+
+	// The keys of terms, sorted, for finding terms by prefix.
+	vocabulary []string
+}
+
+// posting records how many times a term appears in a document.
+type posting struct {
+	doc   int
+	count int
+}
+
+// New creates an index of the given documents.
+func New(docs []Document) *Index {
+	idx := &Index{
+		docs:  docs,
+		terms: map[string][]posting{},
+	}
+
+	for i, doc := range docs {
+		idx.titles = append(idx.titles, Terms(doc.Title))
+
+		counts := map[string]int{}
+		var order []string
+		for _, word := range words(doc.Text) {
+			if counts[word] == 0 {
+				order = append(order, word)
+			}
+			counts[word]++
+		}
+		for _, term := range order {
+			idx.terms[term] = append(idx.terms[term], posting{doc: i, count: counts[term]})
+		}
+	}
+
+	for term := range idx.terms {
+		idx.vocabulary = append(idx.vocabulary, term)
+	}
+	sort.Strings(idx.vocabulary)
+
+	return idx
+}
+
+// Len returns the number of documents in the index.
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search returns up to limit documents matching every term in query, best
+// matches first. Each query term matches any word starting with it. Matches
+// in titles rank above matches in the text, which are ranked by how often the
+// matching words appear.
+func (idx *Index) Search(query string, limit int) []Result {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	scores := make([]int, len(idx.docs))
+	matched := make([]int, len(idx.docs))
+	for _, term := range terms {
+		termScores := map[int]int{}
+
+		for i, title := range idx.titles {
+			if hasPrefix(title, term) {
+				termScores[i] += 10
+			}
+		}
+
+		start := sort.SearchStrings(idx.vocabulary, term)
+		for _, word := range idx.vocabulary[start:] {
+			if !strings.HasPrefix(word, term) {
+				break
+			}
+			for _, p := range idx.terms[word] {
+				termScores[p.doc] += p.count
+			}
+		}
+
+		for doc, score := range termScores {
+			scores[doc] += score
+			matched[doc]++
+		}
+	}
+
+	var found []int
+	for doc := range idx.docs {
+		if matched[doc] == len(terms) {
+			found = append(found, doc)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if scores[found[i]] != scores[found[j]] {
+			return scores[found[i]] > scores[found[j]]
+		}
+		return idx.docs[found[i]].Title < idx.docs[found[j]].Title
+	})
+	if len(found) > limit {
+		found = found[:limit]
+	}
+
+	results := make([]Result, 0, len(found))
+	for _, doc := range found {
+		results = append(results, Result{
+			Document: idx.docs[doc],
+			Snippet:  snippet(idx.docs[doc].Text, terms),
+		})
+	}
+
+	return results
+}
+
+// Terms splits text into unique lowercase terms, in order of first
+// appearance. Terms are separated by anything other than a letter or digit,
+// and terms shorter than MinTermLength are dropped.
+func Terms(text string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, word := range words(text) {
+		if !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// words splits text into lowercase words of at least MinTermLength
+// characters.
+func words(text string) []string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if utf8.RuneCountInString(word) >= MinTermLength {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// hasPrefix returns true if any of the words start with prefix.
+func hasPrefix(words []string, prefix string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// span is the position of a word in a string.
+type span struct {
+	start, end int
+}
+
+// snippet returns an HTML excerpt of text around the first word matching one
+// of terms, with every matching word highlighted. The excerpt starts at the
+// beginning of text if no word matches.
+func snippet(text string, terms []string) string {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		if isSeparator(r) {
+			if start >= 0 {
+				spans = append(spans, span{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(text)})
+	}
+	if len(spans) == 0 {
+		return ""
+	}
+
+	matches := func(s span) bool {
+		word := strings.ToLower(text[s.start:s.end])
+		for _, term := range terms {
+			if strings.HasPrefix(word, term) {
+				return true
+			}
+		}
+		return false
+	}
+
+	first := 0
+	for i, s := range spans {
+		if matches(s) {
+			first = i
+			break
+		}
+	}
+	lo, hi := max(first-snippetContext, 0), min(first+snippetContext+1, len(spans))
+
+	var sb strings.Builder
+	if lo > 0 {
+		sb.WriteString("… ")
+	}
+	pos := spans[lo].start
+	for _, s := range spans[lo:hi] {
+		sb.WriteString(html.EscapeString(text[pos:s.start]))
+		word := html.EscapeString(text[s.start:s.end])
+		if matches(s) {
+			sb.WriteString("<mark>" + word + "</mark>")
+		} else {
+			sb.WriteString(word)
+		}
+		pos = s.end
+	}
+	if hi < len(spans) {
+		sb.WriteString(" …")
+	}
+
+	return sb.String()
+}
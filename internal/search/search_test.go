@@ -0,0 +1,48 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/search"
+)
+
+func titles(results []search.Result) []string {
+	var titles []string
+	for _, result := range results {
+		titles = append(titles, result.Title)
+	}
+	return titles
+}
+
+func TestTerms(t *testing.T) {
+	terms := search.Terms("Go's <b>generics</b>: a go-to GO feature in Go 1.18")
+
+	assert.SliceEqual(t, "terms", []string{"go", "generics", "to", "feature", "in", "18"}, terms)
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := search.New([]search.Document{
+		{Title: "Testing", Text: "Write tests with the testing package. Tests run with go test."},
+		{Title: "Generics", Text: "Generics make code reusable. Test them too."},
+		{Title: "Channels", Text: "Channels connect goroutines."},
+	})
+
+	assert.Equal(t, "len", 3, idx.Len())
+
+	assert.SliceEqual(t, "prefix match ranked by title then count", []string{"Testing", "Generics"}, titles(idx.Search("test", 10)))
+	assert.SliceEqual(t, "every term must match", []string{"Generics"}, titles(idx.Search("test reusable", 10)))
+	assert.SliceEqual(t, "limit", []string{"Testing"}, titles(idx.Search("test", 1)))
+	assert.Equal(t, "no match", 0, len(idx.Search("closures", 10)))
+	assert.Equal(t, "empty query", 0, len(idx.Search("  ", 10)))
+}
+
+func TestIndex_Search_Snippet(t *testing.T) {
+	text := "one two three four five six seven eight nine ten eleven twelve thirteen fourteen <generic> sixteen"
+	idx := search.New([]search.Document{{Title: "Numbers", Text: text}})
+
+	results := idx.Search("gener", 10)
+
+	assert.Equal(t, "result count", 1, len(results))
+	assert.Equal(t, "snippet", "… three four five six seven eight nine ten eleven twelve thirteen fourteen &lt;<mark>generic</mark>&gt; sixteen", results[0].Snippet)
+}
@@ -1,8 +1,11 @@
 package server
 
 import (
+	"log"
 	"sync"
 
+	"github.com/haleyrc/stele/internal/htmlutil"
+	"github.com/haleyrc/stele/internal/search"
 	"github.com/haleyrc/stele/internal/site"
 )
 
@@ -11,6 +14,7 @@ import (
 type SiteCache struct {
 	mu        sync.RWMutex
 	site      *site.Site
+	index     *search.Index
 	lastError error
 	sourceDir string
 	opts      site.SiteOptions
@@ -25,6 +29,7 @@ func NewSiteCache(sourceDir string, opts site.SiteOptions) (*SiteCache, error) {
 
 	return &SiteCache{
 		site:      initialSite,
+		index:     newSearchIndex(initialSite, sourceDir, opts),
 		sourceDir: sourceDir,
 		opts:      opts,
 	}, nil
@@ -37,9 +42,16 @@ func (c *SiteCache) Get() (*site.Site, error) {
 	return c.site, c.lastError
 }
 
-// Set updates the cached site and error state.
+// Search returns the search index for the last good site.
+func (c *SiteCache) Search() *search.Index {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.index
+}
+
+// Set updates the cached site, search index, and error state.
 // If err is non-nil, the site is not updated (preserves last good state).
-func (c *SiteCache) Set(s *site.Site, err error) {
+func (c *SiteCache) Set(s *site.Site, index *search.Index, err error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -47,14 +59,74 @@ func (c *SiteCache) Set(s *site.Site, err error) {
 		c.lastError = err
 	} else {
 		c.site = s
+		c.index = index
 		c.lastError = nil
 	}
 }
 
-// Reload attempts to reload the site from the source directory.
-// Returns an error if reload fails, but preserves the last good site.
+// Reload attempts to reload the site and rebuild its search index from the
+// source directory. Returns an error if reload fails, but preserves the last
+// good site.
 func (c *SiteCache) Reload() error {
 	newSite, err := site.New(c.sourceDir, c.opts)
-	c.Set(newSite, err)
-	return err
+	if err != nil {
+		c.Set(nil, nil, err)
+		return err
+	}
+
+	c.Set(newSite, newSearchIndex(newSite, c.sourceDir, c.opts), nil)
+	return nil
+}
+
+// newSearchIndex builds a search index of the posts and notes in s. Drafts and
+// scheduled posts are always searchable, so if opts leaves them out of s, they
+// are loaded separately and marked as hidden. Errors loading them are logged
+// rather than returned, since they don't affect the site being served, and
+// the index is left without them.
+func newSearchIndex(s *site.Site, sourceDir string, opts site.SiteOptions) *search.Index {
+	posts := s.Posts
+	if !opts.IncludeDrafts || !opts.IncludeScheduled {
+		opts.IncludeDrafts, opts.IncludeScheduled = true, true
+		// Only the text of the posts left out of s is needed. Notes are the
+		// same either way so they're taken from s, and there's no need to
+		// load dates, process images, or render diagrams.
+		opts.NotesExperiment = false
+		opts.GitDates = false
+		opts.ImageCache = ""
+		opts.DiagramCache = ""
+
+		if all, err := site.New(sourceDir, opts); err != nil {
+			log.Printf("ERR: drafts and scheduled posts aren't searchable: %v", err)
+		} else {
+			posts = all.Posts
+		}
+	}
+
+	var docs []search.Document
+	for _, post := range posts {
+		hidden := true
+		if served := s.Posts.GetBySlug(post.Slug); served != nil {
+			// Served posts are indexed as served, with any embedded
+			// notes included.
+			post, hidden = served, false
+		}
+		docs = append(docs, search.Document{
+			Type:   site.SearchPost,
+			Title:  post.Frontmatter.Title,
+			URL:    "/posts/" + post.Slug,
+			Draft:  post.Frontmatter.Draft,
+			Hidden: hidden,
+			Text:   htmlutil.Text(post.Content),
+		})
+	}
+	for _, note := range s.Notes {
+		docs = append(docs, search.Document{
+			Type:  site.SearchNote,
+			Title: note.Frontmatter.Title,
+			URL:   "/notes/" + note.Slug,
+			Text:  htmlutil.Text(note.Content),
+		})
+	}
+
+	return search.New(docs)
 }
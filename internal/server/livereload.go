@@ -2,10 +2,12 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -49,6 +51,7 @@ func NewLiveReloader(
 		return nil, err
 	}
 	lr.watcher = watcher
+	lr.watchIncludes(nil)
 
 	return lr, nil
 }
@@ -68,6 +71,7 @@ func (lr *LiveReloader) Start(ctx context.Context) {
 // ListenAndServe starts the HTTP server with live reload enabled.
 func (lr *LiveReloader) ListenAndServe(ctx context.Context) error {
 	log.Printf("Listening on http://localhost:%s", lr.port)
+	log.Printf("Search posts, drafts, and notes at http://localhost:%s/__dev__/search", lr.port)
 	srv := httpserver.New(lr.port, lr)
 	return srv.ListenAndServe(ctx)
 }
@@ -82,6 +86,9 @@ func (lr *LiveReloader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "/__dev__/reload.js":
 		lr.handleScript(w, r)
 		return
+	case "/__dev__/search":
+		lr.handleSearch(w, r)
+		return
 	}
 
 	// Get site and check for errors
@@ -107,11 +114,28 @@ func (lr *LiveReloader) reload() {
 	} else {
 		log.Println("Site reloaded successfully")
 	}
+	lr.watchIncludes(err)
 
 	// Broadcast reload event (even on error, so error page displays)
 	lr.broadcast("reload")
 }
 
+// watchIncludes watches the files included by the content of the current
+// site for changes. If err is from a reload that failed while including
+// snippets, the files it tried to include are watched too, so that creating
+// a missing file reloads the site.
+func (lr *LiveReloader) watchIncludes(err error) {
+	var files []string
+	if s, _ := lr.cache.Get(); s != nil {
+		files = append(files, s.Includes...)
+	}
+	var includeErr *site.IncludeError
+	if errors.As(err, &includeErr) {
+		files = append(files, includeErr.Files...)
+	}
+	lr.watcher.WatchIncludes(files)
+}
+
 // renderErrorPage renders a simple error page when site fails to reload.
 func (lr *LiveReloader) renderErrorPage(w http.ResponseWriter, r *http.Request, err error) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -140,6 +164,68 @@ func (lr *LiveReloader) renderErrorPage(w http.ResponseWriter, r *http.Request,
 </html>`, html.EscapeString(err.Error()))
 }
 
+// searchLimit is the maximum number of results shown on the dev search page.
+const searchLimit = 50
+
+// handleSearch serves a page for searching the posts, drafts, and notes in the
+// site using the query in the q parameter.
+func (lr *LiveReloader) handleSearch(w http.ResponseWriter, r *http.Request) {
+	index := lr.cache.Search()
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	var results strings.Builder
+	if query == "" {
+		fmt.Fprintf(&results, "<p class=\"meta\">Searching %d posts and notes, including drafts.</p>\n", index.Len())
+	} else {
+		found := index.Search(query, searchLimit)
+		fmt.Fprintf(&results, "<p class=\"meta\">%d result(s) for &quot;%s&quot;</p>\n<ul>\n", len(found), html.EscapeString(query))
+		for _, result := range found {
+			title := html.EscapeString(result.Title)
+			if !result.Hidden {
+				title = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(result.URL), title)
+			}
+
+			labels := []string{result.Type}
+			if result.Draft {
+				labels = append(labels, "draft")
+			}
+			if result.Hidden {
+				labels = append(labels, "hidden by --live")
+			}
+
+			fmt.Fprintf(&results, "<li>%s <span class=\"meta\">%s</span><p>%s</p></li>\n", title, strings.Join(labels, " · "), result.Snippet)
+		}
+		results.WriteString("</ul>\n")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Search</title>
+    <script src="/__dev__/reload.js"></script>
+    <style>
+        body { font-family: sans-serif; padding: 2rem; max-width: 800px; margin: 0 auto; }
+        input { width: 100%%; padding: 0.5rem; font-size: 1rem; box-sizing: border-box; }
+        ul { list-style: none; padding: 0; }
+        li { margin-bottom: 1rem; }
+        li p { margin: 0.25rem 0; }
+        .meta { color: #666; font-size: 0.8rem; }
+        mark { background: #fde68a; }
+    </style>
+</head>
+<body>
+    <h1>Search</h1>
+    <form action="/__dev__/search">
+        <input type="search" name="q" value="%s" placeholder="Search posts, drafts, and notes" autofocus>
+    </form>
+    %s
+</body>
+</html>`, html.EscapeString(query), results.String())
+}
+
 // handleScript serves the live reload JavaScript.
 func (lr *LiveReloader) handleScript(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/javascript")
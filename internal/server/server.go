@@ -8,7 +8,12 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/haleyrc/stele/internal/images"
 	"github.com/haleyrc/stele/internal/site"
 )
 
@@ -30,20 +35,29 @@ func NewServer(renderer site.Renderer) *Server {
 
 	// Content endpoints
 	s.HandleFunc("GET /", s.HandleIndex)
+	s.HandleFunc("GET /page/{n}", s.HandleIndex)
 	s.HandleFunc("GET /about", s.HandleAbout)
 	s.HandleFunc("GET /favicon.ico", s.HandleFavicon)
+	s.HandleFunc("GET /images/{path...}", s.HandleImage)
 	s.HandleFunc("GET /manifest.webmanifest", s.HandleManifest)
 	s.HandleFunc("GET /rss.xml", s.HandleRSS)
+	s.HandleFunc("GET /search", s.HandleSearchPage)
+	s.HandleFunc("GET /search.json", s.HandleSearchIndex)
+	s.HandleFunc("GET /sitemap.xml", s.HandleSitemap)
 	s.HandleFunc("GET /notes", s.HandleNotesIndex)
-	s.HandleFunc("GET /notes/{slug}", s.HandleNote)
+	s.HandleFunc("GET /notes/{path...}", s.HandleNote)
+	s.HandleFunc("GET /notes/graph", s.HandleNoteGraphPage)
+	s.HandleFunc("GET /notes/graph.json", s.HandleNoteGraph)
 	s.HandleFunc("GET /notes/tags", s.HandleNoteTagIndex)
 	s.HandleFunc("GET /notes/tags/{tag}", s.HandleNoteTagPage)
 	s.HandleFunc("GET /posts/{slug}", s.HandlePost)
 	s.HandleFunc("GET /posts/{seriesSlug}/{postSlug}", s.HandleSeriesPost)
 	s.HandleFunc("GET /tags", s.HandleTagIndex)
 	s.HandleFunc("GET /tags/{tag}", s.HandleTagPage)
+	s.HandleFunc("GET /tags/{tag}/page/{n}", s.HandleTagPage)
 	s.HandleFunc("GET /archive", s.HandleArchiveIndex)
 	s.HandleFunc("GET /archive/{year}", s.HandleArchivePage)
+	s.HandleFunc("GET /archive/{year}/page/{n}", s.HandleArchivePage)
 	s.HandleFunc("GET /{seriesSlug}", s.HandleSeriesIndex)
 
 	return s
@@ -63,11 +77,34 @@ func (s *Server) renderHTML(w http.ResponseWriter, r *http.Request, handlerName
 	w.Write(buf.Bytes()) // #nosec G104 - Write errors cannot be handled after headers sent
 }
 
-// HandleIndex serves the index page for the site.
+// pageFromRequest returns the page requested by the n path value, or the first
+// page if there isn't one. The second return value is false if there is no
+// such page. The first page is only served without a page number, so page 1
+// is not found.
+func pageFromRequest(r *http.Request, pages []site.Page) (site.Page, bool) {
+	n := r.PathValue("n")
+	if n == "" {
+		return pages[0], true
+	}
+
+	i, err := strconv.Atoi(n)
+	if err != nil || i < 2 || i > len(pages) {
+		return site.Page{}, false
+	}
+	return pages[i-1], true
+}
+
+// HandleIndex serves a page of the index for the site.
 func (s *Server) HandleIndex(w http.ResponseWriter, r *http.Request) {
 	site := SiteFromContext(r.Context())
+	page, ok := pageFromRequest(r, site.HomePages())
+	if !ok {
+		s.Handle404(w, r)
+		return
+	}
+
 	s.renderHTML(w, r, "HandleIndex", func(ctx context.Context, w io.Writer) error {
-		return s.Renderer.RenderIndex(ctx, w, site)
+		return s.Renderer.RenderIndex(ctx, w, site, page)
 	})
 }
 
@@ -89,6 +126,23 @@ func (s *Server) HandleFavicon(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// HandleImage serves an image from the images directory of the site, or a
+// generated variant of one.
+func (s *Server) HandleImage(w http.ResponseWriter, r *http.Request) {
+	site := SiteFromContext(r.Context())
+	if v, ok := images.Find(site.Images, r.URL.Path); ok {
+		http.ServeFile(w, r, v.Path)
+		return
+	}
+
+	path := filepath.Join(site.Dir, images.Dir, filepath.FromSlash(r.PathValue("path")))
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		s.Handle404(w, r)
+		return
+	}
+	http.ServeFile(w, r, path)
+}
+
 // HandleNotesIndex serves the notes index page.
 func (s *Server) HandleNotesIndex(w http.ResponseWriter, r *http.Request) {
 	site := SiteFromContext(r.Context())
@@ -97,20 +151,63 @@ func (s *Server) HandleNotesIndex(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleNote serves a single note page.
+// HandleNote serves a single note page, or the history page for a note if the
+// path ends in /history. Both are handled here since a separate
+// /notes/{slug}/history route would conflict with /notes/tags/{tag}.
 func (s *Server) HandleNote(w http.ResponseWriter, r *http.Request) {
 	site := SiteFromContext(r.Context())
-	note := site.Notes.GetBySlug(r.PathValue("slug"))
-	if note == nil {
+
+	slug, history := strings.CutSuffix(r.PathValue("path"), "/history")
+	note := site.Notes.GetBySlug(slug)
+	if note == nil || (history && !note.Frontmatter.History) {
 		s.Handle404(w, r)
 		return
 	}
 
+	if history {
+		s.renderHTML(w, r, "HandleNote", func(ctx context.Context, w io.Writer) error {
+			return s.Renderer.RenderNoteHistory(ctx, w, site, note)
+		})
+		return
+	}
+
 	s.renderHTML(w, r, "HandleNote", func(ctx context.Context, w io.Writer) error {
 		return s.Renderer.RenderNote(ctx, w, site, note)
 	})
 }
 
+// HandleNoteGraphPage serves the page visualizing the note graph.
+func (s *Server) HandleNoteGraphPage(w http.ResponseWriter, r *http.Request) {
+	site := SiteFromContext(r.Context())
+	if len(site.Notes) == 0 {
+		s.Handle404(w, r)
+		return
+	}
+
+	s.renderHTML(w, r, "HandleNoteGraphPage", func(ctx context.Context, w io.Writer) error {
+		return s.Renderer.RenderNoteGraphPage(ctx, w, site)
+	})
+}
+
+// HandleNoteGraph serves the note graph as JSON.
+func (s *Server) HandleNoteGraph(w http.ResponseWriter, r *http.Request) {
+	site := SiteFromContext(r.Context())
+	if len(site.Notes) == 0 {
+		s.Handle404(w, r)
+		return
+	}
+
+	ctx := r.Context()
+	graph := site.NoteGraph()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := s.Renderer.RenderNoteGraph(ctx, w, site, graph); err != nil {
+		log.Printf("ERR: HandleNoteGraph: %s: %v", r.URL.Path, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
 // HandleNoteTagIndex serves the note tags index page.
 func (s *Server) HandleNoteTagIndex(w http.ResponseWriter, r *http.Request) {
 	site := SiteFromContext(r.Context())
@@ -163,6 +260,43 @@ func (s *Server) HandleRSS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleSearchPage serves the search page.
+func (s *Server) HandleSearchPage(w http.ResponseWriter, r *http.Request) {
+	site := SiteFromContext(r.Context())
+	s.renderHTML(w, r, "HandleSearchPage", func(ctx context.Context, w io.Writer) error {
+		return s.Renderer.RenderSearchPage(ctx, w, site)
+	})
+}
+
+// HandleSearchIndex serves the search index for the site, built from the
+// current state of the site on every request.
+func (s *Server) HandleSearchIndex(w http.ResponseWriter, r *http.Request) {
+	site := SiteFromContext(r.Context())
+	ctx := r.Context()
+	index := site.SearchIndex()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := s.Renderer.RenderSearchIndex(ctx, w, site, index); err != nil {
+		log.Printf("ERR: HandleSearchIndex: %s: %v", r.URL.Path, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// HandleSitemap serves the XML sitemap for the site.
+func (s *Server) HandleSitemap(w http.ResponseWriter, r *http.Request) {
+	site := SiteFromContext(r.Context())
+	ctx := r.Context()
+	sitemap := site.Sitemap()
+
+	w.Header().Set("Content-Type", "application/xml")
+	if err := s.Renderer.RenderSitemap(ctx, w, site, sitemap); err != nil {
+		log.Printf("ERR: HandleSitemap: %s: %v", r.URL.Path, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
+
 // HandlePost serves a single post page.
 func (s *Server) HandlePost(w http.ResponseWriter, r *http.Request) {
 	site := SiteFromContext(r.Context())
@@ -219,7 +353,7 @@ func (s *Server) HandleTagIndex(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleTagPage serves a page showing posts for a specific tag.
+// HandleTagPage serves a page of the posts for a specific tag.
 func (s *Server) HandleTagPage(w http.ResponseWriter, r *http.Request) {
 	site := SiteFromContext(r.Context())
 	tag := r.PathValue("tag")
@@ -230,8 +364,14 @@ func (s *Server) HandleTagPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := pageFromRequest(r, site.Paginate("/tags/"+tag, posts))
+	if !ok {
+		s.Handle404(w, r)
+		return
+	}
+
 	s.renderHTML(w, r, "HandleTagPage", func(ctx context.Context, w io.Writer) error {
-		return s.Renderer.RenderTagPage(ctx, w, site, tag, posts)
+		return s.Renderer.RenderTagPage(ctx, w, site, tag, page)
 	})
 }
 
@@ -243,7 +383,7 @@ func (s *Server) HandleArchiveIndex(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleArchivePage serves a page showing posts for a specific year.
+// HandleArchivePage serves a page of the posts for a specific year.
 func (s *Server) HandleArchivePage(w http.ResponseWriter, r *http.Request) {
 	site := SiteFromContext(r.Context())
 	year := r.PathValue("year")
@@ -254,8 +394,14 @@ func (s *Server) HandleArchivePage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := pageFromRequest(r, site.Paginate("/archive/"+year, posts))
+	if !ok {
+		s.Handle404(w, r)
+		return
+	}
+
 	s.renderHTML(w, r, "HandleArchivePage", func(ctx context.Context, w io.Writer) error {
-		return s.Renderer.RenderArchivePage(ctx, w, site, year, posts)
+		return s.Renderer.RenderArchivePage(ctx, w, site, year, page)
 	})
 }
 
@@ -3,11 +3,15 @@ package server_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/images"
 	"github.com/haleyrc/stele/internal/server"
+	"github.com/haleyrc/stele/internal/site"
 	"github.com/haleyrc/stele/internal/template"
 	"github.com/haleyrc/stele/internal/testutil"
 )
@@ -92,3 +96,202 @@ func TestServer_HandlePost(t *testing.T) {
 		assert.Equal(t, "status code", http.StatusNotFound, rr.Code)
 	})
 }
+
+func TestServer_HandleIndex_Pagination(t *testing.T) {
+	s := testutil.TestSite()
+	s.Config.PageSize = 3
+	renderer := template.NewTemplateRenderer()
+	srv := server.NewServer(renderer)
+
+	tests := map[string]struct {
+		n    string
+		want int
+	}{
+		"first page":        {n: "", want: http.StatusOK},
+		"second page":       {n: "2", want: http.StatusOK},
+		"numbered first":    {n: "1", want: http.StatusNotFound},
+		"past the last":     {n: "3", want: http.StatusNotFound},
+		"not a page number": {n: "two", want: http.StatusNotFound},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/page/"+tc.n, nil)
+			req = req.WithContext(server.WithSite(req.Context(), s))
+			req.SetPathValue("n", tc.n)
+			rr := httptest.NewRecorder()
+
+			srv.HandleIndex(rr, req)
+
+			assert.Equal(t, "status code", tc.want, rr.Code)
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/page/2", nil)
+	req = req.WithContext(server.WithSite(req.Context(), s))
+	req.SetPathValue("n", "2")
+	rr := httptest.NewRecorder()
+
+	srv.HandleIndex(rr, req)
+
+	body := rr.Body.String()
+	assert.True(t, "links to the previous page", strings.Contains(body, `<link rel="prev" href="/">`))
+	assert.True(t, "lists the oldest post", strings.Contains(body, "Testing in Go"))
+}
+
+func TestServer_HandleImage(t *testing.T) {
+	s := testutil.TestSite()
+	s.Dir = t.TempDir()
+	renderer := template.NewTemplateRenderer()
+	srv := server.NewServer(renderer)
+
+	original := filepath.Join(s.Dir, "images", "photo.png")
+	variant := filepath.Join(s.Dir, ".stele", "images", "abc", "480.png")
+	for _, path := range []string{original, variant} {
+		err := os.MkdirAll(filepath.Dir(path), 0750)
+		assert.OK(t, err).Fatal()
+		err = os.WriteFile(path, []byte(filepath.Base(path)), 0600)
+		assert.OK(t, err).Fatal()
+	}
+	s.Images = []*images.Image{{
+		Src: "/images/photo.png",
+		Variants: []images.Variant{
+			{URL: "/images/photo-480w.png", Path: variant},
+			{URL: "/images/photo.png", Path: original},
+		},
+	}}
+
+	tests := map[string]struct {
+		path string
+		code int
+		body string
+	}{
+		"original": {path: "photo.png", code: http.StatusOK, body: "photo.png"},
+		"variant":  {path: "photo-480w.png", code: http.StatusOK, body: "480.png"},
+		"missing":  {path: "missing.png", code: http.StatusNotFound},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/images/"+tc.path, nil)
+			req = req.WithContext(server.WithSite(req.Context(), s))
+			req.SetPathValue("path", tc.path)
+			rr := httptest.NewRecorder()
+
+			srv.HandleImage(rr, req)
+
+			assert.Equal(t, "status code", tc.code, rr.Code)
+			if tc.body != "" {
+				assert.Equal(t, "body", tc.body, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_HandleNote_History(t *testing.T) {
+	s := testutil.TestSite()
+	s.Notes = site.Notes{
+		{Slug: "vim", Frontmatter: site.NoteFrontmatter{Title: "Vim", Tags: []string{}, History: true}},
+		{Slug: "go", Frontmatter: site.NoteFrontmatter{Title: "Go", Tags: []string{}}},
+	}
+	srv := server.NewServer(template.NewTemplateRenderer())
+
+	testCases := []struct {
+		path string
+		want int
+	}{
+		{path: "/notes/vim", want: http.StatusOK},
+		{path: "/notes/vim/history", want: http.StatusOK},
+		{path: "/notes/go/history", want: http.StatusNotFound},
+		{path: "/notes/missing/history", want: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			req = req.WithContext(server.WithSite(req.Context(), s))
+			rr := httptest.NewRecorder()
+
+			srv.ServeHTTP(rr, req)
+
+			assert.Equal(t, "status code", tc.want, rr.Code)
+		})
+	}
+}
+
+func TestServer_HandleNoteGraph(t *testing.T) {
+	s := testutil.TestSite()
+	s.Notes = site.Notes{
+		{Slug: "vim", Frontmatter: site.NoteFrontmatter{Title: "Vim", Tags: []string{}}, Links: []string{"go"}},
+		{Slug: "go", Frontmatter: site.NoteFrontmatter{Title: "Go", Tags: []string{}}},
+	}
+	srv := server.NewServer(template.NewTemplateRenderer())
+
+	req := httptest.NewRequest("GET", "/notes/graph.json", nil)
+	req = req.WithContext(server.WithSite(req.Context(), s))
+	rr := httptest.NewRecorder()
+
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, "status code", http.StatusOK, rr.Code)
+	assert.Equal(t, "content type", "application/json", rr.Header().Get("Content-Type"))
+	assert.True(t, "link edge", strings.Contains(rr.Body.String(), `"source": "vim"`))
+
+	req = httptest.NewRequest("GET", "/notes/graph", nil)
+	req = req.WithContext(server.WithSite(req.Context(), s))
+	rr = httptest.NewRecorder()
+
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, "page status code", http.StatusOK, rr.Code)
+	assert.True(t, "graph container", strings.Contains(rr.Body.String(), `id="note-graph"`))
+}
+
+func TestServer_HandleSearchIndex(t *testing.T) {
+	s := testutil.TestSite()
+	srv := server.NewServer(template.NewTemplateRenderer())
+
+	req := httptest.NewRequest("GET", "/search.json", nil)
+	req = req.WithContext(server.WithSite(req.Context(), s))
+	rr := httptest.NewRecorder()
+
+	srv.ServeHTTP(rr, req)
+
+	assert.Equal(t, "status code", http.StatusOK, rr.Code)
+	assert.Equal(t, "content type", "application/json", rr.Header().Get("Content-Type"))
+	assert.True(t, "post indexed", strings.Contains(rr.Body.String(), `"url":"/posts/getting-started-with-go"`))
+}
+
+func TestLiveReloader_HandleSearch(t *testing.T) {
+	cache, err := server.NewSiteCache("../site/testdata", site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+
+	lr, err := server.NewLiveReloader("0", template.NewTemplateRenderer(), cache)
+	assert.OK(t, err).Fatal()
+
+	req := httptest.NewRequest("GET", "/__dev__/search?q=generics", nil)
+	rr := httptest.NewRecorder()
+
+	lr.ServeHTTP(rr, req)
+
+	assert.Equal(t, "status code", http.StatusOK, rr.Code)
+
+	body := rr.Body.String()
+	assert.True(t, "draft found", strings.Contains(body, "Exploring Go Generics"))
+	assert.True(t, "draft hidden", strings.Contains(body, "post · draft · hidden by --live"))
+	assert.True(t, "match highlighted", strings.Contains(body, "<mark>generics</mark>"))
+}
+
+func TestNewSiteCache_BrokenHiddenDraft(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nHello world.\n",
+		"posts/wip.md":   "---\ntitle: WIP\ndescription: D\ndraft: true\n---\n{{< include \"missing.go\" >}}\n",
+	})
+
+	// The draft fails to load, but it's left out of the site being served, so
+	// only its search results are lost.
+	cache, err := server.NewSiteCache(dir, site.SiteOptions{})
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "indexed", 1, cache.Search().Len())
+
+	assert.OK(t, cache.Reload()).Fatal()
+	assert.Equal(t, "indexed after reload", 1, cache.Search().Len())
+}
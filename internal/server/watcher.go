@@ -2,21 +2,31 @@ package server
 
 import (
 	"context"
+	"io/fs"
 	"log"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"github.com/haleyrc/stele/internal/images"
 )
 
 // Watcher monitors filesystem changes and triggers reload callbacks.
 type Watcher struct {
 	watcher  *fsnotify.Watcher
+	siteDir  string
 	onChange func()
+
+	mu       sync.Mutex
+	includes map[string]bool
 }
 
 // NewWatcher creates a new file watcher for the given site directory.
-// It watches for changes to content files (posts, notes, config) and invokes
+// It watches for changes to content files (posts, notes, config, images) and invokes
 // the onChange callback when relevant files are modified.
 func NewWatcher(siteDir string, onChange func()) (*Watcher, error) {
 	fw, err := fsnotify.NewWatcher()
@@ -24,22 +34,33 @@ func NewWatcher(siteDir string, onChange func()) (*Watcher, error) {
 		return nil, err
 	}
 
-	// Watch directories (fsnotify watches recursively on some platforms)
-	dirs := []string{
-		filepath.Join(siteDir, "posts"),
-		filepath.Join(siteDir, "notes"),
-		siteDir, // For about.md and stele.yaml
+	// fsnotify doesn't watch recursively, so each directory under posts and
+	// notes is watched individually.
+	for _, dir := range []string{filepath.Join(siteDir, "posts"), filepath.Join(siteDir, "notes")} {
+		if err := addRecursive(fw, dir); err != nil {
+			_ = fw.Close() // #nosec G104 - Cleanup error not actionable
+			return nil, err
+		}
 	}
 
-	for _, dir := range dirs {
-		if err := fw.Add(dir); err != nil {
+	// The images directory is optional. If it doesn't exist yet, it's
+	// watched once it's created.
+	if imagesDir := filepath.Join(siteDir, images.Dir); isDir(imagesDir) {
+		if err := addRecursive(fw, imagesDir); err != nil {
 			_ = fw.Close() // #nosec G104 - Cleanup error not actionable
 			return nil, err
 		}
 	}
 
+	// For about.md and stele.yaml
+	if err := fw.Add(siteDir); err != nil {
+		_ = fw.Close() // #nosec G104 - Cleanup error not actionable
+		return nil, err
+	}
+
 	return &Watcher{
 		watcher:  fw,
+		siteDir:  siteDir,
 		onChange: onChange,
 	}, nil
 }
@@ -72,8 +93,19 @@ func (w *Watcher) Start(ctx context.Context) {
 					return
 				}
 
-				// Filter to relevant file types
-				if w.isRelevantFile(event.Name) {
+				// Start watching new directories so notes and posts
+				// created in them trigger reloads
+				if event.Has(fsnotify.Create) && isDir(event.Name) && w.isWatchedTree(event.Name) {
+					if err := addRecursive(w.watcher, event.Name); err != nil {
+						log.Printf("watcher error: %v", err)
+					}
+				}
+
+				// Filter to relevant file types. Any change to an image
+				// is relevant since pages include the dimensions of the
+				// images they reference, and any change to an included
+				// file is relevant since pages include its code.
+				if w.isRelevantFile(event.Name) || w.isImage(event.Name) || w.isInclude(event.Name) {
 					// Debounce rapid-fire saves
 					debounce.Reset(100 * time.Millisecond)
 				}
@@ -91,6 +123,34 @@ func (w *Watcher) Start(ctx context.Context) {
 	}()
 }
 
+// WatchIncludes replaces the files included by the content of the site with
+// paths, watching the directory of each so that changes to them trigger
+// reloads even when they live outside of the posts and notes directories. The
+// nearest existing parent of a directory that doesn't exist yet is watched
+// instead, so creating a missing file, or a directory on the way to it, also
+// triggers a reload.
+func (w *Watcher) WatchIncludes(paths []string) {
+	includes := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		includes[path] = true
+
+		// Editors often save by replacing the file, which would remove a
+		// watch on the file itself, so the directory is watched instead.
+		dir := filepath.Dir(path)
+		for !isDir(dir) && filepath.Dir(dir) != dir {
+			dir = filepath.Dir(dir)
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			log.Printf("watcher error: %v", err)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.includes = includes
+}
+
 // isRelevantFile checks if the given file path should trigger a reload.
 func (w *Watcher) isRelevantFile(path string) bool {
 	ext := filepath.Ext(path)
@@ -101,3 +161,58 @@ func (w *Watcher) isRelevantFile(path string) bool {
 		base == "index.yaml" ||
 		base == "stele.yaml"
 }
+
+// isWatchedTree checks if the given path is inside the posts, notes, or images
+// directory.
+func (w *Watcher) isWatchedTree(path string) bool {
+	for _, dir := range []string{"posts", "notes", images.Dir} {
+		if isInside(filepath.Join(w.siteDir, dir), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isImage checks if the given path is inside the images directory.
+func (w *Watcher) isImage(path string) bool {
+	return isInside(filepath.Join(w.siteDir, images.Dir), path)
+}
+
+// isInclude checks if the given path is a file included by the content of
+// the site, or a directory containing one.
+func (w *Watcher) isInclude(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	path = filepath.Clean(path)
+	for include := range w.includes {
+		if isInside(path, include) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInside checks if path is inside dir.
+func isInside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// addRecursive watches dir and all of its subdirectories.
+func addRecursive(fw *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return fw.Add(path)
+	})
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
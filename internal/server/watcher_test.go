@@ -0,0 +1,131 @@
+package server_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/server"
+	"github.com/haleyrc/stele/internal/site"
+	"github.com/haleyrc/stele/internal/template"
+)
+
+// writeSite writes each of the files, keyed by path, into a new temporary
+// site directory along with a minimal site config and the notes directory
+// the watcher expects.
+func writeSite(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "notes"), 0750)
+	assert.OK(t, err).Fatal()
+	files["stele.yaml"] = "title: T\nauthor: A\ndescription: D\nbaseURL: https://example.com\n"
+	for path, contents := range files {
+		writeFile(t, filepath.Join(dir, path), contents)
+	}
+
+	return dir
+}
+
+// writeFile writes contents to path, creating any missing directories.
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0750)
+	assert.OK(t, err).Fatal()
+	err = os.WriteFile(path, []byte(contents), 0600)
+	assert.OK(t, err).Fatal()
+}
+
+// startWatcher starts a watcher for the site in dir that reloads cache on
+// each change. It returns a channel that receives the result of each reload.
+func startWatcher(t *testing.T, dir string, cache *server.SiteCache) (*server.Watcher, <-chan error) {
+	t.Helper()
+
+	reloads := make(chan error, 10)
+	w, err := server.NewWatcher(dir, func() { reloads <- cache.Reload() })
+	assert.OK(t, err).Fatal()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	w.Start(ctx)
+
+	return w, reloads
+}
+
+// waitForReload waits for the next reload and returns its result.
+func waitForReload(t *testing.T, reloads <-chan error) error {
+	t.Helper()
+
+	select {
+	case err := <-reloads:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+		return nil
+	}
+}
+
+func TestWatcher_EmbeddedNote(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/vim.md":   "---\ntitle: Vim\ntags: []\n---\nUse hjkl.\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\n![[vim]]\n",
+	})
+
+	cache, err := server.NewSiteCache(dir, site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+	_, reloads := startWatcher(t, dir, cache)
+
+	writeFile(t, filepath.Join(dir, "notes", "vim.md"), "---\ntitle: Vim\ntags: []\n---\nUse the arrow keys.\n")
+	assert.OK(t, waitForReload(t, reloads)).Fatal()
+
+	s, err := cache.Get()
+	assert.OK(t, err).Fatal()
+	assert.True(t, "embed updated", strings.Contains(s.Posts.GetBySlug("hello").Content, "Use the arrow keys."))
+}
+
+// waitFor polls the site in cache until cond returns true.
+func waitFor(t *testing.T, cache *server.SiteCache, desc string, cond func(*site.Site, error) bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond(cache.Get()) {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", desc)
+}
+
+func TestLiveReloader_MissingInclude(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nHi.\n",
+	})
+
+	cache, err := server.NewSiteCache(dir, site.SiteOptions{})
+	assert.OK(t, err).Fatal()
+	lr, err := server.NewLiveReloader("0", template.NewTemplateRenderer(), cache)
+	assert.OK(t, err).Fatal()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	lr.Start(ctx)
+
+	// The directive is added before the file, and the directory, it includes
+	// exist.
+	writeFile(t, filepath.Join(dir, "posts", "hello.md"), "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\n"+
+		"{{< include \"examples/server/main.go\" func=\"main\" >}}\n")
+	waitFor(t, cache, "failed reload", func(_ *site.Site, err error) bool {
+		return err != nil
+	})
+
+	writeFile(t, filepath.Join(dir, "examples", "server", "main.go"), "package main\n\nfunc main() {}\n")
+	waitFor(t, cache, "included file", func(s *site.Site, err error) bool {
+		return err == nil && strings.Contains(s.Posts.GetBySlug("hello").Content, "func main() {}")
+	})
+}
@@ -14,6 +14,10 @@ import (
 type About struct {
 	// The rendered HTML content of the about page.
 	Content string
+
+	// The parsed source of the about page, kept until it's rendered with the
+	// rest of the site.
+	doc *markdown.Document
 }
 
 // LoadAbout loads the about.md file from the site directory and returns the
@@ -29,13 +33,19 @@ func LoadAbout(dir string) (*About, error) {
 		return nil, fmt.Errorf("load about: %s: %w", path, err)
 	}
 
+	doc, err := markdown.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("load about: %w", err)
+	}
+
 	var content strings.Builder
-	if err := markdown.Parse(path, &content); err != nil {
+	if err := doc.Render(&content); err != nil {
 		return nil, fmt.Errorf("load about: %w", err)
 	}
 
 	about := &About{
 		Content: content.String(),
+		doc:     doc,
 	}
 
 	return about, nil
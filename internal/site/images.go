@@ -0,0 +1,45 @@
+package site
+
+import (
+	"fmt"
+
+	"github.com/haleyrc/stele/internal/images"
+)
+
+// processImages rewrites the local images in the about page, notes, and posts
+// to use responsive variants, generating any variants that aren't already
+// cached.
+func (s *Site) processImages() error {
+	p := images.NewProcessor(s.Dir, s.Opts.ImageCache)
+
+	rewrite := func(content *string) error {
+		rewritten, err := p.Rewrite(*content)
+		if err != nil {
+			return err
+		}
+		*content = rewritten
+		return nil
+	}
+
+	if s.About != nil {
+		if err := rewrite(&s.About.Content); err != nil {
+			return fmt.Errorf("site: process images: about: %w", err)
+		}
+	}
+
+	for _, note := range s.Notes {
+		if err := rewrite(&note.Content); err != nil {
+			return fmt.Errorf("site: process images: %s: %w", note.Path, err)
+		}
+	}
+
+	for _, post := range s.Posts {
+		if err := rewrite(&post.Content); err != nil {
+			return fmt.Errorf("site: process images: %s: %w", post.Path, err)
+		}
+	}
+
+	s.Images = p.Images()
+
+	return nil
+}
@@ -3,11 +3,14 @@ package site
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/haleyrc/stele/internal/diff"
 	"github.com/haleyrc/stele/internal/markdown"
 )
 
@@ -21,6 +24,10 @@ type NoteFrontmatter struct {
 
 	// Whether the note should be pinned to the top of the notes index.
 	Pinned bool `yaml:"pinned"`
+
+	// Whether to generate a page showing the revision history of the note.
+	// Requires the site to be in a git repository.
+	History bool `yaml:"history"`
 }
 
 // Validate checks that the frontmatter contains all required fields and that
@@ -44,17 +51,82 @@ type Note struct {
 	Frontmatter NoteFrontmatter
 
 	// The URL-safe slug for the note. Used to generate note URLs
-	// (/notes/{slug}.html).
+	// (/notes/{slug}.html). Notes in subdirectories include the directory in
+	// their slug (e.g. go/concurrency/channels).
 	Slug string
 
 	// The rendered HTML content of the note.
 	Content string
+
+	// The path to the markdown source file for the note.
+	Path string
+
+	// When the source file was first committed to git. Zero unless git dates
+	// are enabled and the file has been committed.
+	Created time.Time
+
+	// When the source file was last committed to git. Zero unless git dates
+	// are enabled and the file has been committed.
+	Modified time.Time
+
+	// The commits that changed the note, newest first. Only loaded for notes
+	// with history enabled.
+	Revisions []NoteRevision
+
+	// The notes and posts that link to the note with wiki links, sorted by
+	// title.
+	Backlinks []Backlink
+
+	// The slugs of the notes the note links to with wiki links, in order of
+	// first appearance.
+	Links []string
+
+	// The targets of wiki links and embeds in the note that could not be
+	// resolved. Embed targets include the heading if there is one.
+	UnresolvedLinks []string
+
+	// The directories containing the note, outermost first. Empty for notes
+	// at the top level of the notes directory.
+	Breadcrumbs []Breadcrumb
+
+	// The parsed source of the note, kept until it's rendered with the rest of
+	// the site.
+	doc *markdown.Document
+}
+
+// Backlink is a note or post that links to a note.
+type Backlink struct {
+	// The title of the linking note or post.
+	Title string
+
+	// The URL of the linking note or post.
+	URL string
+}
+
+// NoteRevision is a single commit in the history of a note.
+type NoteRevision struct {
+	// The full hash of the commit.
+	Hash string
+
+	// When the commit was made.
+	Date time.Time
+
+	// The first line of the commit message.
+	Subject string
+
+	// The changes made to the markdown source of the note by the commit.
+	Diff []diff.Line
 }
 
 // LoadNote loads the file at path and returns the parsed note.
 func LoadNote(path string) (*Note, error) {
+	doc, err := markdown.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("load note: %w", err)
+	}
+
 	var fm NoteFrontmatter
-	if err := markdown.ParseFrontmatter(path, &fm); err != nil {
+	if err := doc.Frontmatter(&fm); err != nil {
 		return nil, fmt.Errorf("load note: %w", err)
 	}
 
@@ -63,7 +135,7 @@ func LoadNote(path string) (*Note, error) {
 	}
 
 	var content strings.Builder
-	if err := markdown.Parse(path, &content); err != nil {
+	if err := doc.Render(&content); err != nil {
 		return nil, fmt.Errorf("load note: %w", err)
 	}
 
@@ -71,6 +143,8 @@ func LoadNote(path string) (*Note, error) {
 		Frontmatter: fm,
 		Slug:        strings.TrimSuffix(filepath.Base(path), ".md"),
 		Content:     content.String(),
+		Path:        path,
+		doc:         doc,
 	}
 
 	return note, nil
@@ -80,9 +154,11 @@ func LoadNote(path string) (*Note, error) {
 // Notes are sorted alphabetically by title.
 type Notes []*Note
 
-// LoadNotes loads all markdown files in the given directory and returns the
-// parsed notes. If the directory does not exist, returns an empty slice with
-// no error.
+// LoadNotes loads all markdown files in the given directory and its
+// subdirectories and returns the parsed notes. The slug of each note is its
+// path relative to dir without the extension, and an index.md file takes the
+// slug of its directory. If the directory does not exist, returns an empty
+// slice with no error.
 func LoadNotes(dir string) (Notes, error) {
 	if _, err := os.Stat(dir); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -91,24 +167,66 @@ func LoadNotes(dir string) (Notes, error) {
 		return nil, fmt.Errorf("load notes: %w", err)
 	}
 
-	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
-	if err != nil {
-		return nil, fmt.Errorf("load notes: %w", err)
-	}
-
 	var notes Notes
-	for _, path := range paths {
+	bySlug := map[string]*Note{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+
 		note, err := LoadNote(path)
 		if err != nil {
-			return nil, fmt.Errorf("load notes: %w", err)
+			return err
+		}
+
+		note.Slug, err = noteSlug(dir, path)
+		if err != nil {
+			return err
+		}
+		if other, ok := bySlug[note.Slug]; ok {
+			return fmt.Errorf("%s and %s both have the slug %q", other.Path, note.Path, note.Slug)
 		}
+		bySlug[note.Slug] = note
+
 		notes = append(notes, note)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load notes: %w", err)
+	}
+
+	for _, note := range notes {
+		note.Breadcrumbs = notes.breadcrumbs(note)
 	}
 
 	notes.Sort()
 	return notes, nil
 }
 
+// noteSlug returns the slug for the note at path within the notes directory.
+func noteSlug(dir, path string) (string, error) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return "", err
+	}
+
+	slug := strings.TrimSuffix(filepath.ToSlash(rel), ".md")
+	if slug == "index" {
+		return "", fmt.Errorf("%s: an index note is not allowed at the top level of the notes directory", path)
+	}
+
+	return strings.TrimSuffix(slug, "/index"), nil
+}
+
 // Len returns the length of the notes slice.
 func (n Notes) Len() int {
 	return len(n)
@@ -130,6 +248,37 @@ func (n Notes) Sort() {
 	sort.Sort(n)
 }
 
+// RecentlyUpdated returns up to maxCount notes with a known modification time,
+// most recently modified first. If maxCount is less than or equal to 0, all
+// such notes are returned.
+func (n Notes) RecentlyUpdated(maxCount int) Notes {
+	var updated Notes
+	for _, note := range n {
+		if !note.Modified.IsZero() {
+			updated = append(updated, note)
+		}
+	}
+
+	sort.SliceStable(updated, func(i, j int) bool {
+		return updated[i].Modified.After(updated[j].Modified)
+	})
+
+	if maxCount > 0 && maxCount < len(updated) {
+		updated = updated[:maxCount]
+	}
+	return updated
+}
+
+// HasHistory returns true if any note has history enabled.
+func (n Notes) HasHistory() bool {
+	for _, note := range n {
+		if note.Frontmatter.History {
+			return true
+		}
+	}
+	return false
+}
+
 // GetBySlug returns the note with the given slug, or nil if not found.
 func (n Notes) GetBySlug(slug string) *Note {
 	for i := range n {
@@ -1,6 +1,8 @@
 package site_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/haleyrc/assert"
@@ -212,3 +214,86 @@ func TestLoadNotes_NonexistentDirectory(t *testing.T) {
 
 	assert.Equal(t, "notes count", 0, len(notes))
 }
+
+// writeNotes writes a note with the given title for each path into a new
+// temporary notes directory.
+func writeNotes(t *testing.T, titles map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for path, title := range titles {
+		err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0750)
+		assert.OK(t, err).Fatal()
+		err = os.WriteFile(filepath.Join(dir, path), []byte("---\ntitle: "+title+"\ntags: []\n---\n"), 0600)
+		assert.OK(t, err).Fatal()
+	}
+
+	return dir
+}
+
+func TestLoadNotes_Nested(t *testing.T) {
+	dir := writeNotes(t, map[string]string{
+		"vim.md":                     "Vim",
+		"go/index.md":                "Go",
+		"go/concurrency/channels.md": "Channels",
+		".hidden/secret.md":          "Secret",
+	})
+
+	notes, err := site.LoadNotes(dir)
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "notes count", 3, len(notes))
+	assert.True(t, "index slug", notes.GetBySlug("go") != nil)
+
+	channels := notes.GetBySlug("go/concurrency/channels")
+	assert.True(t, "nested slug", channels != nil)
+	assert.SliceEqual(t, "breadcrumbs", []site.Breadcrumb{
+		{Title: "Go", URL: "/notes/go"},
+		{Title: "concurrency"},
+	}, channels.Breadcrumbs)
+
+	assert.Equal(t, "top-level breadcrumbs", 0, len(notes.GetBySlug("vim").Breadcrumbs))
+	assert.True(t, "has hierarchy", notes.HasHierarchy())
+}
+
+func TestLoadNotes_DuplicateSlug(t *testing.T) {
+	dir := writeNotes(t, map[string]string{
+		"go.md":       "Go",
+		"go/index.md": "Go Index",
+	})
+
+	_, err := site.LoadNotes(dir)
+	assert.Error(t, err, `both have the slug "go"`)
+}
+
+func TestLoadNotes_TopLevelIndex(t *testing.T) {
+	dir := writeNotes(t, map[string]string{"index.md": "Index"})
+
+	_, err := site.LoadNotes(dir)
+	assert.Error(t, err, "index note is not allowed")
+}
+
+func TestNotes_Tree(t *testing.T) {
+	notes := site.Notes{
+		{Slug: "vim", Frontmatter: site.NoteFrontmatter{Title: "Vim"}},
+		{Slug: "go", Frontmatter: site.NoteFrontmatter{Title: "Go"}},
+		{Slug: "go/testing", Frontmatter: site.NoteFrontmatter{Title: "Testing"}},
+		{Slug: "go/concurrency/channels", Frontmatter: site.NoteFrontmatter{Title: "Channels"}},
+		{Slug: "algorithms/sorting", Frontmatter: site.NoteFrontmatter{Title: "Sorting"}},
+	}
+
+	tree := notes.Tree()
+
+	assert.Equal(t, "root notes", 1, len(tree.Notes))
+	assert.Equal(t, "root note", "vim", tree.Notes[0].Slug)
+	assert.Equal(t, "root children", 2, len(tree.Children))
+
+	algorithms, golang := tree.Children[0], tree.Children[1]
+	assert.Equal(t, "directory without index", "algorithms", algorithms.Title())
+	assert.True(t, "no index", algorithms.Index == nil)
+	assert.Equal(t, "directory with index", "Go", golang.Title())
+	assert.Equal(t, "go notes", 1, len(golang.Notes))
+	assert.Equal(t, "go children", 1, len(golang.Children))
+	assert.Equal(t, "nested directory", "concurrency", golang.Children[0].Name)
+	assert.Equal(t, "nested note", "Channels", golang.Children[0].Notes[0].Frontmatter.Title)
+}
@@ -0,0 +1,141 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Note graph node types.
+const (
+	// NoteGraphNote is a node for a note.
+	NoteGraphNote = "note"
+
+	// NoteGraphTagHub is a node for a tag, which the notes with the tag are
+	// connected to.
+	NoteGraphTagHub = "tag"
+)
+
+// Note graph edge types.
+const (
+	// NoteGraphLink is an edge from a note to a note it links to.
+	NoteGraphLink = "link"
+
+	// NoteGraphTag is an edge from a note to the node for one of its tags.
+	NoteGraphTag = "tag"
+)
+
+// NoteGraph represents the notes in a site as a graph, with edges for the
+// links between notes and the tags they share.
+//
+// Rather than connecting every pair of notes that share a tag, each shared tag
+// has a node of its own that the notes with the tag are connected to. This
+// keeps the number of edges proportional to the number of tags on the notes,
+// where connecting each pair would grow with the square of the number of notes
+// with a tag.
+type NoteGraph struct {
+	// The notes in the site, followed by the tags shared by two or more notes.
+	Nodes []NoteGraphNode `json:"nodes"`
+
+	// The connections between nodes.
+	Edges []NoteGraphEdge `json:"edges"`
+}
+
+// NoteGraphNode represents a single note or tag in a note graph.
+type NoteGraphNode struct {
+	// The slug of the note, or "tag:" followed by the tag.
+	ID string `json:"id"`
+
+	// The type of the node, either NoteGraphNote or NoteGraphTagHub.
+	Type string `json:"type"`
+
+	// The title of the note, or the tag.
+	Title string `json:"title"`
+
+	// The URL of the note, or of the page listing the notes with the tag.
+	URL string `json:"url"`
+
+	// The tags of the note. Not set for tags.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// NoteGraphEdge represents a connection between two nodes in a note graph.
+type NoteGraphEdge struct {
+	// The ID of the node the edge starts at. This is always a note, and for
+	// link edges, the note containing the link.
+	Source string `json:"source"`
+
+	// The ID of the node the edge ends at.
+	Target string `json:"target"`
+
+	// The type of the edge, either NoteGraphLink or NoteGraphTag.
+	Type string `json:"type"`
+}
+
+// NewNoteGraph creates a new note graph for the given site. Note nodes are
+// listed before tag nodes, and link edges before tag edges.
+func NewNoteGraph(s *Site) *NoteGraph {
+	graph := &NoteGraph{
+		Nodes: []NoteGraphNode{},
+		Edges: []NoteGraphEdge{},
+	}
+
+	for _, note := range s.Notes {
+		graph.Nodes = append(graph.Nodes, NoteGraphNode{
+			ID:    note.Slug,
+			Type:  NoteGraphNote,
+			Title: note.Frontmatter.Title,
+			URL:   "/notes/" + note.Slug,
+			Tags:  note.Frontmatter.Tags,
+		})
+	}
+
+	for _, note := range s.Notes {
+		for _, target := range note.Links {
+			graph.Edges = append(graph.Edges, NoteGraphEdge{
+				Source: note.Slug,
+				Target: target,
+				Type:   NoteGraphLink,
+			})
+		}
+	}
+
+	// A tag on a single note doesn't connect it to anything, so it's left out.
+	for _, entry := range s.Notes.IndexByTag() {
+		if len(entry.Notes) < 2 {
+			continue
+		}
+
+		id := "tag:" + entry.Key
+		graph.Nodes = append(graph.Nodes, NoteGraphNode{
+			ID:    id,
+			Type:  NoteGraphTagHub,
+			Title: entry.Key,
+			URL:   "/notes/tags/" + url.PathEscape(entry.Key),
+		})
+		for _, note := range entry.Notes {
+			graph.Edges = append(graph.Edges, NoteGraphEdge{
+				Source: note.Slug,
+				Target: id,
+				Type:   NoteGraphTag,
+			})
+		}
+	}
+
+	return graph
+}
+
+// Render writes the note graph as JSON to the provided writer.
+func (g *NoteGraph) Render(w io.Writer) error {
+	bytes, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return fmt.Errorf("note graph: render: %w", err)
+	}
+
+	if _, err := w.Write(bytes); err != nil {
+		return fmt.Errorf("note graph: render: %w", err)
+	}
+
+	return nil
+}
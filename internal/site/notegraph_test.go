@@ -0,0 +1,54 @@
+package site_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+func TestNoteGraph(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/vim.md":     "---\ntitle: Vim\ntags: [editors, tools]\n---\nSee [[editors]], [[editors]], and [[hello]].\n",
+		"notes/emacs.md":   "---\ntitle: Emacs\ntags: [editors, tools]\n---\nLike [[vim]].\n",
+		"notes/editors.md": "---\ntitle: Editors\ntags: [tools, reading]\n---\nText editors.\n",
+		"posts/hello.md":   "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nHi.\n",
+	})
+
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+
+	graph := s.NoteGraph()
+
+	var ids []string
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.ID)
+	}
+	assert.SliceEqual(t, "nodes", []string{"editors", "emacs", "vim", "tag:editors", "tag:tools"}, ids)
+	assert.Equal(t, "note type", site.NoteGraphNote, graph.Nodes[0].Type)
+	assert.Equal(t, "tag type", site.NoteGraphTagHub, graph.Nodes[3].Type)
+	assert.Equal(t, "tag url", "/notes/tags/editors", graph.Nodes[3].URL)
+
+	var edges []string
+	for _, edge := range graph.Edges {
+		edges = append(edges, edge.Source+" "+edge.Type+" "+edge.Target)
+	}
+	assert.SliceEqual(t, "edges", []string{
+		"emacs link vim",
+		"vim link editors",
+		"emacs tag tag:editors",
+		"vim tag tag:editors",
+		"editors tag tag:tools",
+		"emacs tag tag:tools",
+		"vim tag tag:tools",
+	}, edges)
+
+	var buf bytes.Buffer
+	assert.OK(t, graph.Render(&buf)).Fatal()
+
+	var parsed site.NoteGraph
+	assert.OK(t, json.Unmarshal(buf.Bytes(), &parsed)).Fatal()
+	assert.Equal(t, "rendered url", "/notes/vim", parsed.Nodes[2].URL)
+}
@@ -0,0 +1,126 @@
+package site
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Breadcrumb is a directory containing a note.
+type Breadcrumb struct {
+	// The title of the directory's index note, or the directory name if it
+	// has none.
+	Title string
+
+	// The URL of the directory's index note, or empty if it has none.
+	URL string
+}
+
+// breadcrumbs returns the breadcrumbs for each directory containing note.
+func (n Notes) breadcrumbs(note *Note) []Breadcrumb {
+	var crumbs []Breadcrumb
+
+	dirs := strings.Split(note.Slug, "/")
+	for i := 1; i < len(dirs); i++ {
+		slug := strings.Join(dirs[:i], "/")
+
+		crumb := Breadcrumb{Title: dirs[i-1]}
+		if index := n.GetBySlug(slug); index != nil {
+			crumb.Title = index.Frontmatter.Title
+			crumb.URL = "/notes/" + slug
+		}
+		crumbs = append(crumbs, crumb)
+	}
+
+	return crumbs
+}
+
+// HasHierarchy returns true if any note is in a subdirectory of the notes
+// directory.
+func (n Notes) HasHierarchy() bool {
+	for _, note := range n {
+		if strings.Contains(note.Slug, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NoteTree is a directory in the hierarchy of notes.
+type NoteTree struct {
+	// The name of the directory. Empty for the root of the tree.
+	Name string
+
+	// The note for the directory's index.md file, if it has one.
+	Index *Note
+
+	// The notes in the directory, excluding the index note, sorted
+	// alphabetically by title.
+	Notes Notes
+
+	// The subdirectories of the directory, sorted by title ignoring case.
+	Children []*NoteTree
+}
+
+// Title returns the title of the directory's index note, or the directory
+// name if it has none.
+func (t *NoteTree) Title() string {
+	if t.Index != nil {
+		return t.Index.Frontmatter.Title
+	}
+	return t.Name
+}
+
+// Tree arranges the notes into a tree of directories based on their slugs.
+func (n Notes) Tree() *NoteTree {
+	root := &NoteTree{}
+	dirs := map[string]*NoteTree{"": root}
+
+	var dir func(slug string) *NoteTree
+	dir = func(slug string) *NoteTree {
+		if tree, ok := dirs[slug]; ok {
+			return tree
+		}
+		parent := dir(parentSlug(slug))
+		tree := &NoteTree{Name: path.Base(slug)}
+		parent.Children = append(parent.Children, tree)
+		dirs[slug] = tree
+		return tree
+	}
+
+	// Every directory containing notes needs to be known before index notes
+	// can be told apart from regular notes.
+	for _, note := range n {
+		dir(parentSlug(note.Slug))
+	}
+	for _, note := range n {
+		if tree, ok := dirs[note.Slug]; ok {
+			tree.Index = note
+		} else {
+			parent := dir(parentSlug(note.Slug))
+			parent.Notes = append(parent.Notes, note)
+		}
+	}
+
+	root.sort()
+	return root
+}
+
+func (t *NoteTree) sort() {
+	t.Notes.Sort()
+	sort.SliceStable(t.Children, func(i, j int) bool {
+		return strings.ToLower(t.Children[i].Title()) < strings.ToLower(t.Children[j].Title())
+	})
+	for _, child := range t.Children {
+		child.sort()
+	}
+}
+
+// parentSlug returns the slug of the directory containing the note or
+// directory with the given slug, or an empty string for the root.
+func parentSlug(slug string) string {
+	if i := strings.LastIndex(slug, "/"); i >= 0 {
+		return slug[:i]
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package site
+
+import (
+	"strconv"
+	"strings"
+)
+
+// defaultHomePageSize is the number of posts shown on the home page when
+// pagination is disabled.
+const defaultHomePageSize = 11
+
+// Page is a single page of a paginated list of posts.
+type Page struct {
+	// The posts on the page.
+	Posts Posts
+
+	// The number of the page, starting at 1.
+	Number int
+
+	// The total number of pages in the list.
+	Total int
+
+	// The URL of the first page e.g. "/" or "/tags/go". Later pages are
+	// served from {Base}/page/{Number}.
+	Base string
+}
+
+// URL returns the URL of page n of the list.
+func (p Page) URL(n int) string {
+	if n <= 1 {
+		return p.Base
+	}
+	return strings.TrimSuffix(p.Base, "/") + "/page/" + strconv.Itoa(n)
+}
+
+// HasPrev returns true if there is a page before this one.
+func (p Page) HasPrev() bool {
+	return p.Number > 1
+}
+
+// HasNext returns true if there is a page after this one.
+func (p Page) HasNext() bool {
+	return p.Number < p.Total
+}
+
+// Numbers returns the numbers of every page in the list.
+func (p Page) Numbers() []int {
+	numbers := make([]int, p.Total)
+	for i := range numbers {
+		numbers[i] = i + 1
+	}
+	return numbers
+}
+
+// Paginate splits the posts into pages of at most size posts, with the first
+// page at base. If size is zero or less, all of the posts are on a single
+// page. There is always at least one page, even if there are no posts.
+func (p Posts) Paginate(base string, size int) []Page {
+	if size <= 0 || len(p) <= size {
+		return []Page{{Posts: p, Number: 1, Total: 1, Base: base}}
+	}
+
+	total := (len(p) + size - 1) / size
+	pages := make([]Page, 0, total)
+	for i := 0; i < total; i++ {
+		pages = append(pages, Page{
+			Posts:  p[i*size : min((i+1)*size, len(p))],
+			Number: i + 1,
+			Total:  total,
+			Base:   base,
+		})
+	}
+
+	return pages
+}
+
+// Paginate splits the posts into pages using the configured page size, with
+// the first page at base.
+func (s *Site) Paginate(base string, posts Posts) []Page {
+	return posts.Paginate(base, s.Config.PageSize)
+}
+
+// HomePages returns the pages of the home page. If pagination is disabled,
+// there is a single page with only the most recent posts.
+func (s *Site) HomePages() []Page {
+	if s.Config.PageSize <= 0 {
+		return s.Posts.Recent(defaultHomePageSize).Paginate("/", 0)
+	}
+	return s.Paginate("/", s.Posts)
+}
@@ -0,0 +1,73 @@
+package site_test
+
+import (
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+func TestPosts_Paginate(t *testing.T) {
+	posts := site.Posts{
+		{Slug: "e"},
+		{Slug: "d"},
+		{Slug: "c"},
+		{Slug: "b"},
+		{Slug: "a"},
+	}
+
+	pages := posts.Paginate("/tags/go", 2)
+	assert.Equal(t, "page count", 3, len(pages))
+
+	var slugs [][]string
+	for i, page := range pages {
+		assert.Equal(t, "number", i+1, page.Number)
+		assert.Equal(t, "total", 3, page.Total)
+
+		var pageSlugs []string
+		for _, post := range page.Posts {
+			pageSlugs = append(pageSlugs, post.Slug)
+		}
+		slugs = append(slugs, pageSlugs)
+	}
+	assert.SliceEqual(t, "page 1", []string{"e", "d"}, slugs[0])
+	assert.SliceEqual(t, "page 2", []string{"c", "b"}, slugs[1])
+	assert.SliceEqual(t, "page 3", []string{"a"}, slugs[2])
+
+	assert.False(t, "first page has prev", pages[0].HasPrev())
+	assert.True(t, "first page has next", pages[0].HasNext())
+	assert.True(t, "last page has prev", pages[2].HasPrev())
+	assert.False(t, "last page has next", pages[2].HasNext())
+	assert.SliceEqual(t, "numbers", []int{1, 2, 3}, pages[1].Numbers())
+
+	assert.Equal(t, "first page URL", "/tags/go", pages[1].URL(1))
+	assert.Equal(t, "later page URL", "/tags/go/page/3", pages[1].URL(3))
+}
+
+func TestPosts_Paginate_Disabled(t *testing.T) {
+	posts := site.Posts{{Slug: "b"}, {Slug: "a"}}
+
+	pages := posts.Paginate("/", 0)
+	assert.Equal(t, "page count", 1, len(pages))
+	assert.Equal(t, "posts", 2, len(pages[0].Posts))
+	assert.Equal(t, "later page URL", "/page/2", pages[0].URL(2))
+
+	pages = site.Posts{}.Paginate("/", 2)
+	assert.Equal(t, "empty page count", 1, len(pages))
+	assert.Equal(t, "empty total", 1, pages[0].Total)
+}
+
+func TestSite_HomePages(t *testing.T) {
+	s, err := site.New("testdata", site.SiteOptions{IncludeDrafts: true})
+	assert.OK(t, err).Fatal()
+
+	pages := s.HomePages()
+	assert.Equal(t, "unpaginated page count", 1, len(pages))
+	assert.Equal(t, "unpaginated posts", len(s.Posts), len(pages[0].Posts))
+
+	s.Config.PageSize = 3
+	pages = s.HomePages()
+	assert.Equal(t, "page count", 3, len(pages))
+	assert.Equal(t, "last page posts", 1, len(pages[2].Posts))
+	assert.Equal(t, "last page URL", "/page/3", pages[2].URL(3))
+}
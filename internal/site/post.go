@@ -3,15 +3,22 @@ package site
 import (
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/haleyrc/stele/internal/markdown"
 )
 
 // PostFrontmatter represents the YAML frontmatter in a markdown post file.
 type PostFrontmatter struct {
+	// An optional list of dated notes describing changes made to the post
+	// after it was published.
+	Changelog []ChangelogEntry `yaml:"changelog"`
+
 	// A short description of the post.
 	Description string `yaml:"description"`
 
@@ -19,14 +26,44 @@ type PostFrontmatter struct {
 	// server, but are not included in production builds.
 	Draft bool `yaml:"draft"`
 
+	// Whether footnotes are shown as sidenotes in the margin next to the text
+	// that references them instead of at the end of the post.
+	Sidenotes bool `yaml:"sidenotes"`
+
 	// A list of tags to associate with the post.
 	Tags []string `yaml:"tags"`
 
-	// The "authored date" for the post.
+	// The "authored date" for the post. May be a date (YYYY-MM-DD) or a full
+	// RFC 3339 timestamp.
 	Timestamp time.Time `yaml:"date"`
 
+	// Whether the date was given without a time. Date-only values are parsed
+	// as midnight UTC until they are localized.
+	DateOnly bool `yaml:"-"`
+
 	// The title of the post.
 	Title string `yaml:"title"`
+
+	// An optional date when the post was last revised. Must be after the
+	// authored date. Accepts the same formats as the authored date.
+	Updated time.Time `yaml:"updated"`
+
+	// Whether the updated date was given without a time.
+	UpdatedDateOnly bool `yaml:"-"`
+}
+
+// UnmarshalYAML decodes the frontmatter and records whether dates were given
+// without a time.
+func (fm *PostFrontmatter) UnmarshalYAML(value *yaml.Node) error {
+	type plain PostFrontmatter
+	if err := value.Decode((*plain)(fm)); err != nil {
+		return err
+	}
+
+	fm.DateOnly = isDateOnly(mappingValue(value, "date"))
+	fm.UpdatedDateOnly = isDateOnly(mappingValue(value, "updated"))
+
+	return nil
 }
 
 // Validate checks that the frontmatter contains all required fields and that
@@ -44,13 +81,109 @@ func (fm *PostFrontmatter) Validate() error {
 		if !fm.Timestamp.IsZero() {
 			return fmt.Errorf("drafts must not have a timestamp")
 		}
+		if !fm.Updated.IsZero() {
+			return fmt.Errorf("drafts must not have an updated date")
+		}
 	} else if fm.Timestamp.IsZero() {
 		return fmt.Errorf("posts must have a timestamp")
 	}
 
+	if !fm.Updated.IsZero() && !fm.Updated.After(fm.Timestamp) {
+		return fmt.Errorf("updated date must be after the post date")
+	}
+
+	for i, entry := range fm.Changelog {
+		if entry.Date.IsZero() {
+			return fmt.Errorf("changelog entry %d must have a date", i+1)
+		}
+		if entry.Note == "" {
+			return fmt.Errorf("changelog entry %d must have a note", i+1)
+		}
+	}
+
+	return nil
+}
+
+// Localize converts the post's dates to loc. Date-only values are
+// reinterpreted as midnight in loc rather than midnight UTC.
+func (fm *PostFrontmatter) Localize(loc *time.Location) {
+	fm.Timestamp = localizeTime(fm.Timestamp, fm.DateOnly, loc)
+	fm.DateOnly = false
+
+	fm.Updated = localizeTime(fm.Updated, fm.UpdatedDateOnly, loc)
+	fm.UpdatedDateOnly = false
+
+	for i := range fm.Changelog {
+		fm.Changelog[i].Localize(loc)
+	}
+}
+
+// ChangelogEntry describes a single change made to a published post.
+type ChangelogEntry struct {
+	// When the change was made. Accepts the same formats as the post date.
+	Date time.Time `yaml:"date"`
+
+	// Whether the date was given without a time.
+	DateOnly bool `yaml:"-"`
+
+	// A short description of the change.
+	Note string `yaml:"note"`
+}
+
+// UnmarshalYAML decodes the entry and records whether the date was given
+// without a time.
+func (ce *ChangelogEntry) UnmarshalYAML(value *yaml.Node) error {
+	type plain ChangelogEntry
+	if err := value.Decode((*plain)(ce)); err != nil {
+		return err
+	}
+
+	ce.DateOnly = isDateOnly(mappingValue(value, "date"))
+
+	return nil
+}
+
+// Localize converts the entry's date to loc.
+func (ce *ChangelogEntry) Localize(loc *time.Location) {
+	ce.Date = localizeTime(ce.Date, ce.DateOnly, loc)
+	ce.DateOnly = false
+}
+
+// dateOnlyPattern matches YAML timestamps that contain only a date.
+var dateOnlyPattern = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}$`)
+
+// isDateOnly returns true if node is a scalar containing a date without a
+// time.
+func isDateOnly(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.ScalarNode && dateOnlyPattern.MatchString(node.Value)
+}
+
+// mappingValue returns the value for key in a mapping node, or nil if it is
+// not present.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
 	return nil
 }
 
+// localizeTime converts t to loc. If dateOnly is true, t is reinterpreted as
+// midnight in loc on the same calendar date.
+func localizeTime(t time.Time, dateOnly bool, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+
+	if dateOnly {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+
+	return t.In(loc)
+}
+
 // Post represents a blog post.
 type Post struct {
 	// The YAML frontmatter metadata for the post.
@@ -63,14 +196,42 @@ type Post struct {
 	// The rendered HTML content of the post.
 	Content string
 
+	// The path to the markdown source file for the post.
+	Path string
+
 	// The series this post belongs to. Nil for non-series posts.
 	Series *Series
+
+	// Whether the post is published with a date in the future. Scheduled
+	// posts are only included in a site when requested by its options.
+	Scheduled bool
+
+	// When the source file was first committed to git. Zero unless git dates
+	// are enabled and the file has been committed.
+	Created time.Time
+
+	// When the source file was last committed to git. Zero unless git dates
+	// are enabled and the file has been committed.
+	Modified time.Time
+
+	// The targets of wiki links and embeds in the post that could not be
+	// resolved. Embed targets include the heading if there is one.
+	UnresolvedLinks []string
+
+	// The parsed source of the post, kept until it's rendered with the rest of
+	// the site.
+	doc *markdown.Document
 }
 
 // LoadPost loads the file at path and returns the parsed post.
 func LoadPost(path string) (*Post, error) {
+	doc, err := markdown.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("load post: %w", err)
+	}
+
 	var fm PostFrontmatter
-	if err := markdown.ParseFrontmatter(path, &fm); err != nil {
+	if err := doc.Frontmatter(&fm); err != nil {
 		return nil, fmt.Errorf("load post: %w", err)
 	}
 
@@ -83,7 +244,7 @@ func LoadPost(path string) (*Post, error) {
 	}
 
 	var content strings.Builder
-	if err := markdown.Parse(path, &content); err != nil {
+	if err := doc.Render(&content); err != nil {
 		return nil, fmt.Errorf("load post: %w", err)
 	}
 
@@ -91,11 +252,22 @@ func LoadPost(path string) (*Post, error) {
 		Frontmatter: fm,
 		Slug:        strings.TrimSuffix(filepath.Base(path), ".md"),
 		Content:     content.String(),
+		Path:        path,
+		doc:         doc,
 	}
 
 	return post, nil
 }
 
+// LastModified returns when the post was last changed: the updated date if
+// there is one, or the authored date otherwise.
+func (p *Post) LastModified() time.Time {
+	if !p.Frontmatter.Updated.IsZero() {
+		return p.Frontmatter.Updated
+	}
+	return p.Frontmatter.Timestamp
+}
+
 // SeriesPosition returns the 1-based position of this post within its series.
 // Panics if the post is not part of a series.
 func (p *Post) SeriesPosition() int {
@@ -151,9 +323,26 @@ func (p Posts) Len() int {
 }
 
 // Less reports whether the post at index i should sort before the post at index j.
-// Posts are sorted by timestamp in descending order (newest first).
+// Posts are sorted by timestamp in descending order (newest first). Posts with
+// the same timestamp are sorted by slug.
 func (p Posts) Less(i, j int) bool {
-	return p[i].Frontmatter.Timestamp.After(p[j].Frontmatter.Timestamp)
+	ti, tj := p[i].Frontmatter.Timestamp, p[j].Frontmatter.Timestamp
+	if !ti.Equal(tj) {
+		return ti.After(tj)
+	}
+	return p[i].Slug < p[j].Slug
+}
+
+// LastModified returns the most recent last modified date of any post, or the
+// zero time if there are no posts.
+func (p Posts) LastModified() time.Time {
+	var latest time.Time
+	for _, post := range p {
+		if t := post.LastModified(); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
 }
 
 // Recent returns up to maxCount of the most recent posts.
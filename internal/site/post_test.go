@@ -1,7 +1,10 @@
 package site_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/haleyrc/assert"
 	"github.com/haleyrc/stele/internal/site"
@@ -34,6 +37,105 @@ func TestLoadPost_Draft(t *testing.T) {
 	assert.Equal(t, "draft", true, post.Frontmatter.Draft)
 }
 
+func writePost(t *testing.T, frontmatter string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "post.md")
+	err := os.WriteFile(path, []byte("---\n"+frontmatter+"---\n\nContent.\n"), 0600)
+	assert.OK(t, err).Fatal()
+
+	return path
+}
+
+func TestLoadPost_DateFormats(t *testing.T) {
+	dateOnly, err := site.LoadPost(writePost(t, "title: A\ndescription: B\ndate: 2025-03-14\n"))
+	assert.OK(t, err).Fatal()
+	assert.True(t, "date only", dateOnly.Frontmatter.DateOnly)
+
+	full, err := site.LoadPost(writePost(t, "title: A\ndescription: B\ndate: 2025-03-14T09:30:00-04:00\n"))
+	assert.OK(t, err).Fatal()
+	assert.False(t, "date only", full.Frontmatter.DateOnly)
+	assert.Equal(t, "hour (UTC)", 13, full.Frontmatter.Timestamp.UTC().Hour())
+}
+
+func TestPostFrontmatter_Validate_Updated(t *testing.T) {
+	date := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	fm := site.PostFrontmatter{Title: "A", Description: "B", Timestamp: date, Updated: date.Add(24 * time.Hour)}
+	assert.OK(t, fm.Validate())
+
+	fm.Updated = date
+	assert.Error(t, fm.Validate(), "updated date must be after")
+
+	fm = site.PostFrontmatter{Title: "A", Description: "B", Draft: true, Updated: date}
+	assert.Error(t, fm.Validate(), "drafts must not have an updated date")
+}
+
+func TestPostFrontmatter_Validate_Changelog(t *testing.T) {
+	fm := site.PostFrontmatter{
+		Title:       "A",
+		Description: "B",
+		Timestamp:   time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+		Changelog:   []site.ChangelogEntry{{Note: "Fixed a typo."}},
+	}
+	assert.Error(t, fm.Validate(), "changelog entry 1 must have a date")
+
+	fm.Changelog[0] = site.ChangelogEntry{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)}
+	assert.Error(t, fm.Validate(), "changelog entry 1 must have a note")
+}
+
+func TestLoadPost_UpdatedAndChangelog(t *testing.T) {
+	path := writePost(t, `title: A
+description: B
+date: 2025-03-14
+updated: 2025-04-01T10:00:00Z
+changelog:
+  - date: 2025-04-01
+    note: Fixed a typo.
+`)
+
+	post, err := site.LoadPost(path)
+	assert.OK(t, err).Fatal()
+
+	assert.False(t, "updated date only", post.Frontmatter.UpdatedDateOnly)
+	assert.Equal(t, "changelog count", 1, len(post.Frontmatter.Changelog))
+	assert.True(t, "changelog date only", post.Frontmatter.Changelog[0].DateOnly)
+	assert.Equal(t, "changelog note", "Fixed a typo.", post.Frontmatter.Changelog[0].Note)
+	assert.True(t, "last modified", post.LastModified().Equal(post.Frontmatter.Updated))
+}
+
+func TestPostFrontmatter_Localize(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.OK(t, err).Fatal()
+
+	dateOnly := site.PostFrontmatter{Timestamp: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), DateOnly: true}
+	dateOnly.Localize(loc)
+	assert.True(t, "midnight local", dateOnly.Timestamp.Equal(time.Date(2025, 3, 14, 0, 0, 0, 0, loc)))
+
+	full := site.PostFrontmatter{Timestamp: time.Date(2025, 3, 14, 2, 0, 0, 0, time.UTC)}
+	full.Localize(loc)
+	assert.Equal(t, "local day", 13, full.Timestamp.Day())
+	assert.True(t, "same instant", full.Timestamp.Equal(time.Date(2025, 3, 14, 2, 0, 0, 0, time.UTC)))
+}
+
+func TestPostsSorting_SameTimestamp(t *testing.T) {
+	ts := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	posts := site.Posts{
+		{Slug: "charlie", Frontmatter: site.PostFrontmatter{Timestamp: ts}},
+		{Slug: "alpha", Frontmatter: site.PostFrontmatter{Timestamp: ts}},
+		{Slug: "newer", Frontmatter: site.PostFrontmatter{Timestamp: ts.Add(time.Hour)}},
+		{Slug: "bravo", Frontmatter: site.PostFrontmatter{Timestamp: ts}},
+	}
+
+	posts.Sort()
+
+	slugs := make([]string, len(posts))
+	for i, post := range posts {
+		slugs[i] = post.Slug
+	}
+	assert.SliceEqual(t, "slugs", []string{"newer", "alpha", "bravo", "charlie"}, slugs)
+}
+
 func TestPostsSorting(t *testing.T) {
 	// Load posts from testdata
 	posts := site.Posts{}
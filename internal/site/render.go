@@ -0,0 +1,119 @@
+package site
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/haleyrc/stele/internal/diagram"
+	"github.com/haleyrc/stele/internal/markdown"
+	"github.com/haleyrc/stele/internal/snippet"
+)
+
+// IncludeError is an error rendering the content of a site, which may have
+// come from including a snippet.
+type IncludeError struct {
+	// The files included or tried before the error, joined with the site
+	// directory. This includes a file that doesn't exist, so that it can be
+	// watched for being created.
+	Files []string
+
+	// The underlying error.
+	Err error
+}
+
+func (e *IncludeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *IncludeError) Unwrap() error {
+	return e.Err
+}
+
+// renderContent renders the about page, notes, and posts again from the
+// documents parsed when they were loaded, now that the rest of the site is
+// known. Wiki links and embeds are resolved, recording the backlinks for each
+// linked note, include directives are replaced with the code they include
+// from files in the site directory, and diagrams are rendered as SVG if the
+// site options have a diagram cache. It returns the number of snippets
+// included and diagrams rendered.
+//
+// Links that can't be resolved are an error listing each file and target,
+// unless the site options ask for them to be logged as warnings.
+func (s *Site) renderContent() (snippets, diagrams int, err error) {
+	inc := snippet.NewIncluder(s.Dir)
+	l := &linker{
+		site:      s,
+		includes:  markdown.Includes{Includer: inc},
+		backlinks: map[*Note][]Backlink{},
+		done:      map[*Note]bool{},
+	}
+
+	var r *diagram.Renderer
+	if s.Opts.DiagramCache != "" {
+		r = diagram.NewRenderer(s.Opts.DiagramCache)
+		r.ShowErrors = s.Opts.ShowDiagramErrors
+		l.diagrams = markdown.Diagrams{Renderer: r}
+	}
+
+	if s.About != nil && s.About.doc != nil {
+		var content strings.Builder
+		if err := s.About.doc.RenderWith(&content, markdown.RenderOptions{Includes: l.includes, Diagrams: l.diagrams}); err != nil {
+			return 0, 0, fmt.Errorf("site: render content: %w", &IncludeError{Files: inc.Files(), Err: err})
+		}
+		s.About.Content = content.String()
+		s.About.doc = nil
+	}
+
+	for _, note := range s.Notes {
+		if err := l.linkNote(note, nil); err != nil {
+			return 0, 0, fmt.Errorf("site: render content: %w", &IncludeError{Files: inc.Files(), Err: err})
+		}
+	}
+
+	for _, post := range s.Posts {
+		if post.doc == nil {
+			continue
+		}
+		result, err := l.link(post.doc, post.Path, Backlink{Title: post.Frontmatter.Title, URL: "/posts/" + post.Slug}, nil)
+		if err != nil {
+			return 0, 0, fmt.Errorf("site: render content: %w", &IncludeError{Files: inc.Files(), Err: err})
+		}
+		post.Content, post.UnresolvedLinks = result.content, result.unresolved
+		post.doc = nil
+	}
+
+	for note, links := range l.backlinks {
+		sort.SliceStable(links, func(i, j int) bool {
+			return links[i].Title < links[j].Title
+		})
+		note.Backlinks = links
+	}
+
+	var unresolved []string
+	for _, note := range s.Notes {
+		for _, target := range note.UnresolvedLinks {
+			unresolved = append(unresolved, fmt.Sprintf("%s: [[%s]]", note.Path, target))
+		}
+	}
+	for _, post := range s.Posts {
+		for _, target := range post.UnresolvedLinks {
+			unresolved = append(unresolved, fmt.Sprintf("%s: [[%s]]", post.Path, target))
+		}
+	}
+	if len(unresolved) > 0 {
+		if !s.Opts.WarnUnresolvedLinks {
+			return 0, 0, fmt.Errorf("site: render content: unresolved wiki links: %s", strings.Join(unresolved, ", "))
+		}
+		for _, link := range unresolved {
+			log.Printf("WARN: unresolved wiki link: %s", link)
+		}
+	}
+
+	s.Includes = inc.Files()
+	if r != nil {
+		diagrams = r.Count()
+	}
+	return inc.Count(), diagrams, nil
+}
@@ -83,6 +83,10 @@ type RSSFeedChannelItem struct {
 
 	// When the item was published.
 	PubDate string `xml:"pubDate"`
+
+	// When the item was last revised, if it has been updated since it was
+	// published.
+	Updated string `xml:"atom:updated,omitempty"`
 }
 
 // NewRSSFeed creates a new RSS feed for the given site.
@@ -106,7 +110,7 @@ func NewRSSFeed(s *Site) *RSSFeed {
 				s.Config.Author,
 			),
 			Language:      "en",
-			LastBuildDate: time.Now().Format(time.RFC1123),
+			LastBuildDate: s.Now().Format(time.RFC1123Z),
 			Items:         []RSSFeedChannelItem{},
 		},
 	}
@@ -118,7 +122,10 @@ func NewRSSFeed(s *Site) *RSSFeed {
 			GUID:        fmt.Sprintf("%s/posts/%s", s.Config.BaseURL, post.Slug),
 			Description: post.Frontmatter.Description,
 			Category:    post.Frontmatter.Tags,
-			PubDate:     post.Frontmatter.Timestamp.Format(time.RFC1123),
+			PubDate:     post.Frontmatter.Timestamp.Format(time.RFC1123Z),
+		}
+		if !post.Frontmatter.Updated.IsZero() {
+			item.Updated = post.Frontmatter.Updated.Format(time.RFC3339)
 		}
 		rss.Channel.Items = append(rss.Channel.Items, item)
 	}
@@ -5,6 +5,7 @@ import (
 	"encoding/xml"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/haleyrc/assert"
 	"github.com/haleyrc/stele/internal/site"
@@ -105,3 +106,16 @@ func TestSite_RenderRSSFeed(t *testing.T) {
 	assert.Equal(t, "title", "Alice Codes", parsed.Channel.Title)
 	assert.Equal(t, "item count", 4, len(parsed.Channel.Items))
 }
+
+func TestRSS_UpdatedPost(t *testing.T) {
+	s := testutil.TestSite()
+	s.Posts[0].Frontmatter.Updated = s.Posts[0].Frontmatter.Timestamp.Add(24 * time.Hour)
+
+	var buff bytes.Buffer
+	err := site.NewRSSFeed(s).Render(&buff)
+	assert.OK(t, err).Fatal()
+
+	want := "<atom:updated>" + s.Posts[0].Frontmatter.Updated.Format(time.RFC3339) + "</atom:updated>"
+	assert.Equal(t, "updated count", 1, strings.Count(buff.String(), "<atom:updated>"))
+	assert.True(t, "updated value", strings.Contains(buff.String(), want))
+}
@@ -0,0 +1,110 @@
+package site
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/haleyrc/stele/internal/htmlutil"
+	"github.com/haleyrc/stele/internal/search"
+)
+
+// Search document types.
+const (
+	// SearchPost is a search document for a post.
+	SearchPost = "post"
+
+	// SearchNote is a search document for a note.
+	SearchNote = "note"
+)
+
+// SearchIndex represents a full-text search index of the posts and notes in a
+// site. Titles, descriptions, tags, and headings are stored with each
+// document, while body text is stored as an inverted index of terms to keep the
+// index small.
+type SearchIndex struct {
+	// The searchable posts and notes.
+	Documents []SearchDocument `json:"documents"`
+
+	// Each lowercase term in the body text of the documents, mapped to the
+	// positions in Documents of the documents containing it in ascending
+	// order.
+	Terms map[string][]int `json:"terms"`
+}
+
+// SearchDocument represents a single post or note in a search index.
+type SearchDocument struct {
+	// The type of the document, either SearchPost or SearchNote.
+	Type string `json:"type"`
+
+	// The title of the document.
+	Title string `json:"title"`
+
+	// The URL of the document.
+	URL string `json:"url"`
+
+	// The description of the document. Empty for notes.
+	Description string `json:"description,omitempty"`
+
+	// The tags of the document.
+	Tags []string `json:"tags,omitempty"`
+
+	// The text of the headings in the document.
+	Headings []string `json:"headings,omitempty"`
+}
+
+// NewSearchIndex creates a new search index for the given site. Posts are
+// listed before notes.
+func NewSearchIndex(s *Site) *SearchIndex {
+	index := &SearchIndex{
+		Documents: []SearchDocument{},
+		Terms:     map[string][]int{},
+	}
+
+	for _, post := range s.Posts {
+		index.add(SearchDocument{
+			Type:        SearchPost,
+			Title:       post.Frontmatter.Title,
+			URL:         "/posts/" + post.Slug,
+			Description: post.Frontmatter.Description,
+			Tags:        post.Frontmatter.Tags,
+		}, post.Content)
+	}
+
+	for _, note := range s.Notes {
+		index.add(SearchDocument{
+			Type:  SearchNote,
+			Title: note.Frontmatter.Title,
+			URL:   "/notes/" + note.Slug,
+			Tags:  note.Frontmatter.Tags,
+		}, note.Content)
+	}
+
+	return index
+}
+
+// add adds a document with the given rendered content to the index.
+func (i *SearchIndex) add(doc SearchDocument, content string) {
+	doc.Headings = htmlutil.Headings(content)
+
+	n := len(i.Documents)
+	i.Documents = append(i.Documents, doc)
+
+	for _, term := range search.Terms(htmlutil.Text(content)) {
+		i.Terms[term] = append(i.Terms[term], n)
+	}
+}
+
+// Render writes the search index as compact JSON to the provided writer.
+func (i *SearchIndex) Render(w io.Writer) error {
+	bytes, err := json.Marshal(i)
+	if err != nil {
+		return fmt.Errorf("search index: render: %w", err)
+	}
+
+	if _, err := w.Write(bytes); err != nil {
+		return fmt.Errorf("search index: render: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package site_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+func TestSearchIndex(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/vim.md":   "---\ntitle: Vim\ntags: [editors]\n---\n## Motions\n\nUse hjkl to move, or hjkl with a count.\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: A greeting\ndate: 2024-01-01\ntags: [intro]\n---\nHello, *world*! Learning to move in Vim.\n",
+	})
+
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+
+	index := s.SearchIndex()
+
+	assert.Equal(t, "document count", 2, len(index.Documents))
+
+	post := index.Documents[0]
+	assert.Equal(t, "post type", site.SearchPost, post.Type)
+	assert.Equal(t, "post url", "/posts/hello", post.URL)
+	assert.Equal(t, "post description", "A greeting", post.Description)
+	assert.SliceEqual(t, "post tags", []string{"intro"}, post.Tags)
+
+	note := index.Documents[1]
+	assert.Equal(t, "note type", site.SearchNote, note.Type)
+	assert.Equal(t, "note url", "/notes/vim", note.URL)
+	assert.SliceEqual(t, "note headings", []string{"Motions"}, note.Headings)
+
+	assert.SliceEqual(t, "shared term", []int{0, 1}, index.Terms["move"])
+	assert.SliceEqual(t, "repeated term", []int{1}, index.Terms["hjkl"])
+	assert.SliceEqual(t, "markup stripped", []int{0}, index.Terms["world"])
+	assert.Equal(t, "short terms skipped", 0, len(index.Terms["a"]))
+
+	var buf bytes.Buffer
+	assert.OK(t, index.Render(&buf)).Fatal()
+
+	var parsed site.SearchIndex
+	assert.OK(t, json.Unmarshal(buf.Bytes(), &parsed)).Fatal()
+	assert.Equal(t, "rendered title", "Vim", parsed.Documents[1].Title)
+}
@@ -75,11 +75,6 @@ func LoadSeries(dir string, includeDrafts bool) (*Series, error) {
 		}
 	}
 
-	// Sort posts chronologically (oldest first) for series ordering
-	sort.Slice(posts, func(i, j int) bool {
-		return posts[i].Frontmatter.Timestamp.Before(posts[j].Frontmatter.Timestamp)
-	})
-
 	// Update post slugs to include series slug prefix
 	slug := filepath.Base(dir)
 	for _, post := range posts {
@@ -91,6 +86,7 @@ func LoadSeries(dir string, includeDrafts bool) (*Series, error) {
 		Slug:     slug,
 		Posts:    posts,
 	}
+	series.sort()
 
 	// Set series backlink on each post
 	for _, post := range posts {
@@ -100,6 +96,18 @@ func LoadSeries(dir string, includeDrafts bool) (*Series, error) {
 	return series, nil
 }
 
+// sort orders the posts in the series chronologically (oldest first). Posts
+// with the same timestamp are sorted by slug.
+func (s *Series) sort() {
+	sort.Slice(s.Posts, func(i, j int) bool {
+		ti, tj := s.Posts[i].Frontmatter.Timestamp, s.Posts[j].Frontmatter.Timestamp
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return s.Posts[i].Slug < s.Posts[j].Slug
+	})
+}
+
 // AllSeries is a slice of Series pointers.
 type AllSeries []*Series
 
@@ -8,7 +8,15 @@ import (
 	"path/filepath"
 	"time"
 
+	// Embedded so that configured timezones can be resolved on systems
+	// without a timezone database.
+	_ "time/tzdata"
+
 	"gopkg.in/yaml.v3"
+
+	"github.com/haleyrc/stele/internal/diff"
+	"github.com/haleyrc/stele/internal/gitlog"
+	"github.com/haleyrc/stele/internal/images"
 )
 
 // SiteOptions contains configuration options for creating a site.
@@ -16,8 +24,41 @@ type SiteOptions struct {
 	// Whether to include draft posts in the site.
 	IncludeDrafts bool
 
+	// Whether to include scheduled posts (published posts dated after Now) in
+	// the site.
+	IncludeScheduled bool
+
+	// The time used to decide whether a post is scheduled. Drafts are also
+	// stamped with this time. Defaults to the current time if zero.
+	Now time.Time
+
+	// Whether Now was given without a time, in which case it's midnight in
+	// the site's timezone on its date.
+	NowDateOnly bool
+
 	// Whether to enable the experimental notes feature.
 	NotesExperiment bool
+
+	// Whether to read creation and modification times for posts and notes
+	// from the git history of the site directory.
+	GitDates bool
+
+	// The directory in which resized variants of local images are cached. If
+	// empty, images are not processed and are left as-is.
+	ImageCache string
+
+	// The directory in which rendered diagrams are cached. If empty, diagrams
+	// are not rendered and are left as code blocks.
+	DiagramCache string
+
+	// Whether diagrams that fail to render are shown as an error message in
+	// the page instead of failing to load the site.
+	ShowDiagramErrors bool
+
+	// Whether wiki links and embeds that can't be resolved are logged as
+	// warnings instead of failing to load the site. They are recorded in the
+	// UnresolvedLinks of each note and post either way.
+	WarnUnresolvedLinks bool
 }
 
 // Site represents a complete blog site with configuration and content.
@@ -31,6 +72,13 @@ type Site struct {
 	// The root directory of the site content.
 	Dir string
 
+	// The local images referenced by the content of the site, along with
+	// their generated variants. Empty unless images are processed.
+	Images []*images.Image
+
+	// The files included in the content of the site by include directives.
+	Includes []string
+
 	// All notes for the site.
 	Notes Notes
 
@@ -85,6 +133,41 @@ func New(dir string, opts SiteOptions) (*Site, error) {
 	}
 	log.Printf("Loaded %d posts (%v)", len(s.Posts), dur)
 
+	var snippets, diagrams int
+	dur, err = logPhase("Rendering content", func() error {
+		var err error
+		snippets, diagrams, err = s.renderContent()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("new site: %w", err)
+	}
+	log.Printf("Rendered content with %d snippets and %d diagrams (%v)", snippets, diagrams, dur)
+
+	if s.Opts.ImageCache != "" {
+		dur, err = logPhase("Processing images", s.processImages)
+		if err != nil {
+			return nil, fmt.Errorf("new site: %w", err)
+		}
+		log.Printf("Processed %d images (%v)", len(s.Images), dur)
+	}
+
+	if s.Opts.GitDates {
+		dur, err = logPhase("Loading git history", s.loadGitDates)
+		if err != nil {
+			return nil, fmt.Errorf("new site: %w", err)
+		}
+		log.Printf("Loaded git history (%v)", dur)
+	}
+
+	if s.Notes.HasHistory() {
+		dur, err = logPhase("Loading note history", s.loadNoteHistory)
+		if err != nil {
+			return nil, fmt.Errorf("new site: %w", err)
+		}
+		log.Printf("Loaded note history (%v)", dur)
+	}
+
 	log.Printf("Site loaded successfully (%v)", time.Since(siteStart).Round(time.Millisecond))
 
 	return s, nil
@@ -112,7 +195,7 @@ func (s *Site) loadConfig() error {
 func (s *Site) loadAbout() error {
 	about, err := LoadAbout(s.Dir)
 	if err != nil {
-		return fmt.Errorf("site: load about: %w", err)
+		return fmt.Errorf("site: %w", err)
 	}
 	s.About = about
 	return nil
@@ -121,7 +204,7 @@ func (s *Site) loadAbout() error {
 func (s *Site) loadNotes() error {
 	notes, err := LoadNotes(filepath.Join(s.Dir, "notes"))
 	if err != nil {
-		return fmt.Errorf("site: load notes: %w", err)
+		return fmt.Errorf("site: %w", err)
 	}
 	s.Notes = notes
 	return nil
@@ -132,6 +215,10 @@ func (s *Site) loadSeries() error {
 	if err != nil {
 		return fmt.Errorf("site: load series: %w", err)
 	}
+	for _, series := range series {
+		series.Posts = s.schedule(series.Posts)
+		series.sort()
+	}
 	s.Series = series
 	return nil
 }
@@ -140,17 +227,130 @@ func (s *Site) loadPosts() error {
 	// Load standalone posts (markdown files at the root of posts/)
 	posts, err := LoadPosts(filepath.Join(s.Dir, "posts"), s.Opts.IncludeDrafts)
 	if err != nil {
-		return fmt.Errorf("site: load posts: %w", err)
+		return fmt.Errorf("site: %w", err)
 	}
 
-	// Merge series posts with standalone posts
+	// Merge series posts with standalone posts. Series posts have already been
+	// scheduled when the series were loaded.
 	seriesPosts := s.Series.AllPosts()
-	s.Posts = append(posts, seriesPosts...)
+	s.Posts = append(s.schedule(posts), seriesPosts...)
 	s.Posts.Sort()
 
 	return nil
 }
 
+func (s *Site) loadGitDates() error {
+	history, err := gitlog.Load(s.Dir)
+	if err != nil {
+		return fmt.Errorf("site: load git dates: %w", err)
+	}
+
+	lookup := func(path string) (gitlog.Times, error) {
+		rel, err := filepath.Rel(s.Dir, path)
+		if err != nil {
+			return gitlog.Times{}, err
+		}
+		times, _ := history.Lookup(rel)
+		times.Created = localizeTime(times.Created, false, s.Config.Location())
+		times.Modified = localizeTime(times.Modified, false, s.Config.Location())
+		return times, nil
+	}
+
+	for _, post := range s.Posts {
+		times, err := lookup(post.Path)
+		if err != nil {
+			return fmt.Errorf("site: load git dates: %w", err)
+		}
+		post.Created, post.Modified = times.Created, times.Modified
+	}
+
+	for _, note := range s.Notes {
+		times, err := lookup(note.Path)
+		if err != nil {
+			return fmt.Errorf("site: load git dates: %w", err)
+		}
+		note.Created, note.Modified = times.Created, times.Modified
+	}
+
+	return nil
+}
+
+// loadNoteHistory loads the revisions of each note with history enabled and
+// diffs each revision against the one before it. Notes whose history can't be
+// read are left without any revisions.
+func (s *Site) loadNoteHistory() error {
+	for _, note := range s.Notes {
+		if !note.Frontmatter.History {
+			continue
+		}
+
+		rel, err := filepath.Rel(s.Dir, note.Path)
+		if err != nil {
+			return fmt.Errorf("site: load note history: %w", err)
+		}
+
+		// History is extra, so a site outside of a git repository, or
+		// without git installed, is still built without it.
+		revisions, err := gitlog.Revisions(s.Dir, rel)
+		if err != nil {
+			log.Printf("WARN: site: load note history: %s has no history: %v", note.Path, err)
+			continue
+		}
+
+		note.Revisions = make([]NoteRevision, len(revisions))
+		for i, revision := range revisions {
+			var previous string
+			if i+1 < len(revisions) {
+				previous = revisions[i+1].Content
+			}
+
+			note.Revisions[i] = NoteRevision{
+				Hash:    revision.Hash,
+				Date:    localizeTime(revision.Date, false, s.Config.Location()),
+				Subject: revision.Subject,
+				Diff:    diff.Lines(previous, revision.Content),
+			}
+		}
+	}
+
+	return nil
+}
+
+// Now returns the time the site was generated for in the site's timezone. This
+// is the Now option if set, or the current time otherwise.
+func (s *Site) Now() time.Time {
+	if s.Opts.Now.IsZero() {
+		return time.Now().In(s.Config.Location())
+	}
+	return localizeTime(s.Opts.Now, s.Opts.NowDateOnly, s.Config.Location())
+}
+
+// schedule converts post dates to the site's timezone, stamps drafts with the
+// site's current time, marks published posts dated in the future as
+// scheduled, and removes scheduled posts unless they are included by the site
+// options.
+func (s *Site) schedule(posts Posts) Posts {
+	now := s.Now()
+	loc := s.Config.Location()
+
+	var kept Posts
+	for _, post := range posts {
+		post.Frontmatter.Localize(loc)
+
+		if post.Frontmatter.Draft {
+			post.Frontmatter.Timestamp = now
+		} else if post.Frontmatter.Timestamp.After(now) {
+			post.Scheduled = true
+			if !s.Opts.IncludeScheduled {
+				continue
+			}
+		}
+		kept = append(kept, post)
+	}
+
+	return kept
+}
+
 // CopyrightYear returns the year of the earliest post, or the current year if
 // no posts exist.
 func (s *Site) CopyrightYear() int {
@@ -170,11 +370,26 @@ func (s *Site) Manifest() *Manifest {
 	return NewManifest(s)
 }
 
+// NoteGraph creates and returns the graph of notes for the site.
+func (s *Site) NoteGraph() *NoteGraph {
+	return NewNoteGraph(s)
+}
+
 // RSSFeed creates and returns the RSS feed for the site.
 func (s *Site) RSSFeed() *RSSFeed {
 	return NewRSSFeed(s)
 }
 
+// SearchIndex creates and returns the full-text search index for the site.
+func (s *Site) SearchIndex() *SearchIndex {
+	return NewSearchIndex(s)
+}
+
+// Sitemap creates and returns the XML sitemap for the site.
+func (s *Site) Sitemap() *Sitemap {
+	return NewSitemap(s)
+}
+
 // SiteConfig represents the configuration loaded from stele.yaml.
 type SiteConfig struct {
 	// The author of the blog.
@@ -193,11 +408,24 @@ type SiteConfig struct {
 	// A description of the blog's content and/or purpose.
 	Description string `yaml:"description"`
 
+	// The number of posts listed on each page of the home page, tag pages,
+	// and archive pages. Defaults to 0, which disables pagination: tag and
+	// archive pages list every post and the home page lists the most recent
+	// posts.
+	PageSize int `yaml:"pageSize"`
+
 	// Social media links to display on the About page.
 	Social SocialLinks `yaml:"social"`
 
+	// The IANA name of the timezone used to interpret date-only post dates
+	// and to display dates e.g. "America/New_York". Defaults to UTC.
+	Timezone string `yaml:"timezone"`
+
 	// The title/name of the blog.
 	Title string `yaml:"title"`
+
+	// The location of Timezone, resolved when the config is validated.
+	location *time.Location
 }
 
 // SocialLinks contains URLs for social media profiles.
@@ -210,7 +438,8 @@ type SocialLinks struct {
 }
 
 // Validate checks that the site configuration contains all required fields and
-// that field values are valid.
+// that field values are valid. It also resolves the timezone returned by
+// Location.
 func (c *SiteConfig) Validate() error {
 	if c.Author == "" {
 		return fmt.Errorf("site config must have an author")
@@ -233,13 +462,32 @@ func (c *SiteConfig) Validate() error {
 		return fmt.Errorf("site config must have a description")
 	}
 
+	if c.PageSize < 0 {
+		return fmt.Errorf("site config page size must not be negative")
+	}
+
 	if c.Title == "" {
 		return fmt.Errorf("site config must have a title")
 	}
 
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return fmt.Errorf("site config timezone is invalid: %w", err)
+	}
+	c.location = loc
+
 	return nil
 }
 
+// Location returns the configured timezone, or UTC if none is set. The config
+// must have been validated, or UTC is returned regardless of the timezone.
+func (c *SiteConfig) Location() *time.Location {
+	if c.location == nil {
+		return time.UTC
+	}
+	return c.location
+}
+
 // LoadSiteConfig loads the file at path and returns the parsed configuration.
 func LoadSiteConfig(dir string) (*SiteConfig, error) {
 	path := filepath.Join(dir, "stele.yaml")
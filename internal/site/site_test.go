@@ -1,9 +1,18 @@
 package site_test
 
 import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/diff"
+	"github.com/haleyrc/stele/internal/htmlutil"
 	"github.com/haleyrc/stele/internal/site"
 )
 
@@ -55,6 +64,427 @@ func TestNewSite(t *testing.T) {
 	assertAllPostsLoaded(t, site)
 }
 
+func TestNewSite_ScheduledPosts(t *testing.T) {
+	opts := site.SiteOptions{Now: time.Date(2025, 9, 16, 0, 0, 0, 0, time.UTC)}
+
+	live, err := site.New("testdata", opts)
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "post count", 4, len(live.Posts))
+	assert.Equal(t, "latest post", "advanced-go-patterns", live.Posts.Latest().Slug)
+
+	opts.IncludeScheduled = true
+	preview, err := site.New("testdata", opts)
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "post count", 6, len(preview.Posts))
+	assert.True(t, "scheduled", preview.Posts.GetBySlug("getting-started-with-go").Scheduled)
+	assert.False(t, "not scheduled", preview.Posts.GetBySlug("advanced-go-patterns").Scheduled)
+}
+
+func TestNewSite_ScheduledPosts_DateOnlyNow(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2025-03-14\n---\nHi.\n",
+	})
+	err := os.WriteFile(filepath.Join(dir, "stele.yaml"), []byte("title: T\nauthor: A\ndescription: D\nbaseURL: https://example.com\ntimezone: America/New_York\n"), 0600)
+	assert.OK(t, err).Fatal()
+
+	// Midnight UTC is still the previous day in New York.
+	opts := site.SiteOptions{IncludeScheduled: true, Now: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)}
+	s, err := site.New(dir, opts)
+	assert.OK(t, err).Fatal()
+	assert.True(t, "scheduled at midnight UTC", s.Posts.GetBySlug("hello").Scheduled)
+
+	opts.NowDateOnly = true
+	s, err = site.New(dir, opts)
+	assert.OK(t, err).Fatal()
+	assert.False(t, "scheduled on the date", s.Posts.GetBySlug("hello").Scheduled)
+	assert.Equal(t, "now", "2025-03-14T00:00:00-04:00", s.Now().Format(time.RFC3339))
+}
+
+func TestNewSite_ScheduledSeriesPosts(t *testing.T) {
+	s, err := site.New("testdata", site.SiteOptions{
+		Now: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	})
+	assert.OK(t, err).Fatal()
+
+	series := s.Series.GetBySlug("go-basics")
+	assert.Equal(t, "series post count", 1, len(series.Posts))
+	assert.Equal(t, "series post", "go-basics/variables", series.Posts[0].Slug)
+	assert.Equal(t, "post count", 1, len(s.Posts))
+}
+
+func TestNewSite_DraftsStampedWithNow(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	s, err := site.New("testdata", site.SiteOptions{IncludeDrafts: true, Now: now})
+	assert.OK(t, err).Fatal()
+
+	draft := s.Posts.GetBySlug("draft-exploring-go-generics")
+	assert.True(t, "timestamp", now.Equal(draft.Frontmatter.Timestamp))
+	assert.False(t, "scheduled", draft.Scheduled)
+}
+
+// gitSite is a site directory inside a temporary git repository.
+type gitSite struct {
+	t   *testing.T
+	dir string
+}
+
+// newGitSite creates a git repository containing a minimal site config. The
+// test is skipped if git is not installed.
+func newGitSite(t *testing.T) *gitSite {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	g := &gitSite{t: t, dir: t.TempDir()}
+	g.git("", "init", "-q")
+	g.write("stele.yaml", "title: T\nauthor: A\ndescription: D\nbaseURL: https://example.com\n")
+
+	return g
+}
+
+// write writes contents to path within the site without committing it.
+func (g *gitSite) write(path, contents string) {
+	g.t.Helper()
+
+	err := os.MkdirAll(filepath.Join(g.dir, filepath.Dir(path)), 0750)
+	assert.OK(g.t, err).Fatal()
+	err = os.WriteFile(filepath.Join(g.dir, path), []byte(contents), 0600)
+	assert.OK(g.t, err).Fatal()
+}
+
+// commit commits every file in the site with the given author date.
+func (g *gitSite) commit(date, message string) {
+	g.t.Helper()
+
+	g.git(date, "add", "-A")
+	g.git(date, "commit", "-q", "-m", message)
+}
+
+func (g *gitSite) git(date string, args ...string) {
+	g.t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", g.dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		g.t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func TestNewSite_GitDates(t *testing.T) {
+	g := newGitSite(t)
+	g.write("posts/hello.md", "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\n")
+	g.write("notes/vim.md", "---\ntitle: Vim\ntags: []\n---\nOne\n")
+	g.commit("2024-01-01T10:00:00Z", "Initial")
+
+	g.write("notes/vim.md", "---\ntitle: Vim\ntags: []\n---\nTwo\n")
+	g.commit("2024-03-01T10:00:00Z", "Update vim")
+
+	g.write("notes/new.md", "---\ntitle: New\ntags: []\n---\n")
+
+	dir := g.dir
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true, GitDates: true})
+	assert.OK(t, err).Fatal()
+
+	vim := s.Notes.GetBySlug("vim")
+	assert.Equal(t, "vim created", "2024-01-01", vim.Created.Format("2006-01-02"))
+	assert.Equal(t, "vim modified", "2024-03-01", vim.Modified.Format("2006-01-02"))
+	assert.True(t, "uncommitted note", s.Notes.GetBySlug("new").Modified.IsZero())
+	assert.Equal(t, "post modified", "2024-01-01", s.Posts.GetBySlug("hello").Modified.Format("2006-01-02"))
+	assert.Equal(t, "recently updated", 1, len(s.Notes.RecentlyUpdated(0)))
+}
+
+func TestNewSite_NoteHistory(t *testing.T) {
+	g := newGitSite(t)
+	g.write("posts/hello.md", "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\n")
+	g.write("notes/vim.md", "---\ntitle: Vim\ntags: []\nhistory: true\n---\nOne\n")
+	g.write("notes/go.md", "---\ntitle: Go\ntags: []\n---\nOne\n")
+	g.commit("2024-01-01T10:00:00Z", "Add notes")
+
+	g.write("notes/vim.md", "---\ntitle: Vim\ntags: []\nhistory: true\n---\nOne\nTwo\n")
+	g.commit("2024-03-01T10:00:00Z", "Expand vim")
+
+	s, err := site.New(g.dir, site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+
+	assert.Equal(t, "history disabled", 0, len(s.Notes.GetBySlug("go").Revisions))
+
+	revisions := s.Notes.GetBySlug("vim").Revisions
+	assert.Equal(t, "revision count", 2, len(revisions))
+	assert.Equal(t, "newest subject", "Expand vim", revisions[0].Subject)
+	assert.Equal(t, "newest date", "2024-03-01", revisions[0].Date.Format("2006-01-02"))
+
+	var inserted []string
+	for _, line := range revisions[0].Diff {
+		if line.Op == diff.Insert {
+			inserted = append(inserted, line.Text)
+		}
+	}
+	assert.SliceEqual(t, "newest insertions", []string{"Two"}, inserted)
+	assert.Equal(t, "oldest diff length", 6, len(revisions[1].Diff))
+	assert.Equal(t, "oldest diff op", diff.Insert, revisions[1].Diff[0].Op)
+}
+
+func TestNewSite_NoteHistory_NotARepository(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/vim.md":   "---\ntitle: Vim\ntags: []\nhistory: true\n---\nOne\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nHi.\n",
+	})
+
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "revisions", 0, len(s.Notes.GetBySlug("vim").Revisions))
+}
+
+// writeSite writes each of the files, keyed by path, into a new temporary
+// site directory along with a minimal site config.
+func writeSite(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["stele.yaml"] = "title: T\nauthor: A\ndescription: D\nbaseURL: https://example.com\n"
+	for path, contents := range files {
+		err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0750)
+		assert.OK(t, err).Fatal()
+		err = os.WriteFile(filepath.Join(dir, path), []byte(contents), 0600)
+		assert.OK(t, err).Fatal()
+	}
+
+	return dir
+}
+
+func TestNewSite_WikiLinks(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/vim.md":     "---\ntitle: Vim\ntags: []\n---\nSee [[editors]] and [[vim]].\n",
+		"notes/emacs.md":   "---\ntitle: Emacs\ntags: []\n---\nSee [[editors|the overview]] and [[missing]].\n",
+		"notes/editors.md": "---\ntitle: Editors\ntags: []\n---\nRead [[hello]].\n",
+		"posts/hello.md":   "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nMy [[editors]] note.\n",
+	})
+
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true, WarnUnresolvedLinks: true})
+	assert.OK(t, err).Fatal()
+
+	editors := s.Notes.GetBySlug("editors")
+	assert.True(t, "post link rendered", strings.Contains(editors.Content, `<a class="wikilink" href="/posts/hello">Hello</a>`))
+	assert.SliceEqual(t, "backlinks", []site.Backlink{
+		{Title: "Emacs", URL: "/notes/emacs"},
+		{Title: "Hello", URL: "/posts/hello"},
+		{Title: "Vim", URL: "/notes/vim"},
+	}, editors.Backlinks)
+
+	assert.Equal(t, "self link excluded", 0, len(s.Notes.GetBySlug("vim").Backlinks))
+	assert.SliceEqual(t, "unresolved", []string{"missing"}, s.Notes.GetBySlug("emacs").UnresolvedLinks)
+}
+
+func TestNewSite_UnresolvedWikiLinks(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/vim.md":   "---\ntitle: Vim\ntags: []\n---\nSee [[missing]].\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\n![[vim#gone]]\n",
+	})
+
+	_, err := site.New(dir, site.SiteOptions{NotesExperiment: true})
+	assert.Error(t, err, filepath.Join("notes", "vim.md")+": [[missing]]")
+	assert.Error(t, err, filepath.Join("posts", "hello.md")+": [[vim#gone]]")
+
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true, WarnUnresolvedLinks: true})
+	assert.OK(t, err).Fatal()
+	assert.SliceEqual(t, "unresolved", []string{"missing"}, s.Notes.GetBySlug("vim").UnresolvedLinks)
+}
+
+func TestNewSite_WikiEmbeds(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/vim.md":     "---\ntitle: Vim\ntags: []\n---\n## Motions\n\nUse hjkl.\n\n## Modes\n\nNormal mode.\n",
+		"notes/editors.md": "---\ntitle: Editors\ntags: []\n---\n![[vim#motions]]\n\n![[vim#missing]]\n",
+		"posts/hello.md":   "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\n![[editors]]\n",
+	})
+
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true, WarnUnresolvedLinks: true})
+	assert.OK(t, err).Fatal()
+
+	editors := s.Notes.GetBySlug("editors")
+	assert.True(t, "section embedded", strings.Contains(editors.Content, "<h2 id=\"embed-vim-motions\">Motions</h2>\n<p>Use hjkl.</p>"))
+	assert.False(t, "following section excluded", strings.Contains(editors.Content, "Normal mode."))
+	assert.SliceEqual(t, "unresolved", []string{"vim#missing"}, editors.UnresolvedLinks)
+
+	post := s.Posts.GetBySlug("hello")
+	assert.True(t, "nested embed", strings.Contains(post.Content, "Use hjkl."))
+	assert.SliceEqual(t, "backlinks", []site.Backlink{
+		{Title: "Hello", URL: "/posts/hello"},
+	}, editors.Backlinks)
+}
+
+func TestNewSite_WikiEmbedFragments(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/use.md": "---\ntitle: Use\ntags: []\nsidenotes: true\n---\n" +
+			"![Pipeline](/p.png \"Pipeline\"){#fig:a}\n\nSee @fig:a.[^1] And a sidenote.[^2]\n\n" +
+			"[^1]: A footnote.\n\n    ```\n    code\n    ```\n[^2]: A sidenote.\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nIntro.[^1]\n\n![[use]]\n\n[^1]: The post's own footnote.\n",
+	})
+
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+
+	// Every fragment link and label in the page must point at an element in
+	// the page, including those in the embedded note.
+	post := s.Posts.GetBySlug("hello")
+	ids := htmlutil.IDs(post.Content)
+	for _, link := range htmlutil.Links(post.Content) {
+		if id, ok := strings.CutPrefix(link.URL, "#"); ok {
+			assert.True(t, "anchor "+link.URL, ids[id])
+		}
+	}
+	for _, m := range regexp.MustCompile(`for="([^"]*)"`).FindAllStringSubmatch(post.Content, -1) {
+		assert.True(t, "label for "+m[1], ids[m[1]])
+	}
+
+	assert.True(t, "footnote ref", strings.Contains(post.Content, `href="#embed-use-fn:1"`))
+	assert.True(t, "figure ref", strings.Contains(post.Content, `href="#embed-use-fig:a"`))
+	assert.True(t, "sidenote label", strings.Contains(post.Content, `for="embed-use-sn:2"`))
+	assert.True(t, "own footnote", strings.Contains(post.Content, `href="#fn:1"`))
+}
+
+func TestNewSite_WikiEmbedCycle(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/a.md":     "---\ntitle: A\ntags: []\n---\n![[b]]\n",
+		"notes/b.md":     "---\ntitle: B\ntags: []\n---\n![[a]]\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nHi.\n",
+	})
+
+	_, err := site.New(dir, site.SiteOptions{NotesExperiment: true})
+	assert.Error(t, err, "embed cycle: a -> b -> a")
+}
+
+func TestNewSite_Includes(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"about.md":          "{{< include \"examples/hello.sh\" >}}\n",
+		"examples/hello.sh": "# region: greet\necho \"<hi>\"\n# endregion: greet\n",
+		"notes/shell.md":    "---\ntitle: Shell\ntags: []\n---\n{{< include \"examples/hello.sh\" region=\"greet\" >}}\n",
+		"posts/hello.md":    "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\n![[shell]]\n",
+	})
+
+	s, err := site.New(dir, site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+
+	want := "<pre><code class=\"language-bash\">echo &quot;&lt;hi&gt;&quot;\n</code></pre>"
+	assert.True(t, "about", strings.Contains(s.About.Content, want))
+	assert.True(t, "note", strings.Contains(s.Notes.GetBySlug("shell").Content, want))
+	assert.True(t, "embedded in post", strings.Contains(s.Posts.GetBySlug("hello").Content, want))
+	assert.SliceEqual(t, "includes", []string{filepath.Join(dir, "examples", "hello.sh")}, s.Includes)
+}
+
+func TestNewSite_Includes_EmbeddedError(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/all.md":   "---\ntitle: All\ntags: []\n---\n![[shell]]\n",
+		"notes/shell.md": "---\ntitle: Shell\ntags: []\n---\nIntro.\n\n{{< include \"examples/missing.sh\" >}}\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nHi.\n",
+	})
+
+	// The error is from rendering the embedded note, so it names the line in
+	// that note rather than in the note embedding it.
+	_, err := site.New(dir, site.SiteOptions{NotesExperiment: true})
+	assert.Error(t, err, filepath.Join("notes", "shell.md")+`: line 7: include "examples/missing.sh"`)
+
+	var includeErr *site.IncludeError
+	assert.True(t, "include error", errors.As(err, &includeErr))
+	assert.SliceEqual(t, "files", []string{filepath.Join(dir, "examples", "missing.sh")}, includeErr.Files)
+}
+
+func TestNewSite_Diagrams(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"examples/box.txt": "+--+\n|  |\n+--+\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\n" +
+			"```goat\n+--+\n|  |\n+--+\n```\n\n{{< include \"examples/box.txt\" lang=\"goat\" >}}\n",
+	})
+	opts := site.SiteOptions{DiagramCache: filepath.Join(dir, ".stele", "diagrams")}
+
+	s, err := site.New(dir, opts)
+	assert.OK(t, err).Fatal()
+
+	content := s.Posts.GetBySlug("hello").Content
+	assert.Equal(t, "diagrams", 1, strings.Count(content, `<div class="diagram"><svg `))
+	assert.True(t, "included code", strings.Contains(content, "<pre><code class=\"language-goat\">+--+\n"))
+
+	s, err = site.New(dir, site.SiteOptions{})
+	assert.OK(t, err).Fatal()
+	assert.False(t, "without cache", strings.Contains(s.Posts.GetBySlug("hello").Content, "<svg"))
+}
+
+func TestNewSite_Diagrams_Error(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"notes/box.md":   "---\ntitle: Box\ntags: []\n---\n```goat\n+--+\n|\t|\n```\n",
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nHi.\n",
+	})
+	opts := site.SiteOptions{NotesExperiment: true, DiagramCache: filepath.Join(dir, ".stele", "diagrams")}
+
+	_, err := site.New(dir, opts)
+	assert.Error(t, err, filepath.Join("notes", "box.md")+": line 5: diagram: render: line 2, column 2: tabs aren't allowed")
+
+	opts.ShowDiagramErrors = true
+	s, err := site.New(dir, opts)
+	assert.OK(t, err).Fatal()
+	assert.True(t, "error shown", strings.Contains(s.Notes.GetBySlug("box").Content, `<div class="diagram-error" role="alert">`))
+}
+
+func TestNewSite_RenderError(t *testing.T) {
+	dir := writeSite(t, map[string]string{
+		"posts/hello.md": "---\ntitle: Hello\ndescription: D\ndate: 2024-01-01\n---\nHi.\n\n$$\nx\n",
+	})
+
+	_, err := site.New(dir, site.SiteOptions{})
+	assert.Error(t, err, "site: load posts: load post: markdown: render: ")
+	assert.Error(t, err, "hello.md: line 8: math: unclosed $$")
+	assert.False(t, "reported as frontmatter error", strings.Contains(err.Error(), "frontmatter"))
+}
+
+func TestSiteConfig_Timezone(t *testing.T) {
+	config := site.SiteConfig{
+		Author:      "Alice",
+		BaseURL:     "https://example.com",
+		Description: "A blog",
+		Title:       "Blog",
+	}
+
+	assert.OK(t, config.Validate())
+	assert.Equal(t, "default location", time.UTC, config.Location())
+
+	config.Timezone = "America/New_York"
+	assert.OK(t, config.Validate())
+	assert.Equal(t, "location", "America/New_York", config.Location().String())
+
+	config.Timezone = "Mars/Olympus_Mons"
+	assert.Error(t, config.Validate(), "timezone")
+}
+
+func TestLoadSiteConfig_Timezone(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "stele.yaml"), []byte("author: A\nbaseURL: https://example.com\ndescription: D\ntitle: T\ntimezone: Asia/Tokyo\n"), 0600)
+	assert.OK(t, err).Fatal()
+
+	config, err := site.LoadSiteConfig(dir)
+	assert.OK(t, err).Fatal()
+	assert.Equal(t, "location", "Asia/Tokyo", config.Location().String())
+}
+
+func TestSiteConfig_Validate_PageSize(t *testing.T) {
+	config := site.SiteConfig{
+		Author:      "Alice",
+		BaseURL:     "https://example.com",
+		Description: "A blog",
+		Title:       "Blog",
+		PageSize:    10,
+	}
+	assert.OK(t, config.Validate())
+
+	config.PageSize = -1
+	assert.Error(t, config.Validate(), "page size must not be negative")
+}
+
 func TestLoadSiteConfig(t *testing.T) {
 	config, err := site.LoadSiteConfig("testdata")
 	assert.OK(t, err).Fatal()
@@ -0,0 +1,117 @@
+package site
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Sitemap represents an XML sitemap for a site.
+type Sitemap struct {
+	XMLName xml.Name `xml:"urlset"`
+
+	// The sitemap protocol namespace.
+	NS string `xml:"xmlns,attr"`
+
+	// The pages in the site.
+	URLs []SitemapURL `xml:"url"`
+}
+
+// SitemapURL represents a single page in a sitemap.
+type SitemapURL struct {
+	// The absolute URL of the page.
+	Loc string `xml:"loc"`
+
+	// When the page was last modified in W3C datetime format. Omitted for
+	// generated pages without a meaningful modification date.
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// NewSitemap creates a new sitemap for the given site. Posts use their last
+// modified date, notes use their git modification time if known, and pages
+// that list posts use the most recent last modified date of the posts they
+// contain. Every page of a paginated list is included.
+func NewSitemap(s *Site) *Sitemap {
+	sitemap := &Sitemap{
+		NS:   "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs: []SitemapURL{},
+	}
+
+	add := func(path string, lastMod time.Time) {
+		u := SitemapURL{Loc: s.Config.BaseURL + path}
+		if !lastMod.IsZero() {
+			u.LastMod = lastMod.Format(time.RFC3339)
+		}
+		sitemap.URLs = append(sitemap.URLs, u)
+	}
+
+	// Adds every page of a paginated list after the first, which is listed
+	// under its base URL.
+	addPages := func(pages []Page) {
+		for _, page := range pages[1:] {
+			add(page.URL(page.Number), page.Posts.LastModified())
+		}
+	}
+
+	add("/", s.Posts.LastModified())
+	addPages(s.HomePages())
+
+	if s.About != nil {
+		add("/about", time.Time{})
+	}
+
+	if len(s.Notes) > 0 {
+		add("/notes", time.Time{})
+		for _, note := range s.Notes {
+			add("/notes/"+note.Slug, note.Modified)
+		}
+		add("/notes/graph", time.Time{})
+		add("/notes/tags", time.Time{})
+		for _, entry := range s.Notes.IndexByTag() {
+			add("/notes/tags/"+entry.Key, time.Time{})
+		}
+	}
+
+	for _, post := range s.Posts {
+		add("/posts/"+post.Slug, post.LastModified())
+	}
+
+	for _, series := range s.Series {
+		add("/"+series.Slug, series.Posts.LastModified())
+	}
+
+	add("/archive", s.Posts.LastModified())
+	for _, entry := range s.Posts.IndexByYear() {
+		add("/archive/"+entry.Key, entry.Posts.LastModified())
+		addPages(s.Paginate("/archive/"+entry.Key, entry.Posts))
+	}
+
+	if s.Posts.HasTags() {
+		add("/tags", s.Posts.LastModified())
+		for _, entry := range s.Posts.IndexByTag() {
+			add("/tags/"+entry.Key, entry.Posts.LastModified())
+			addPages(s.Paginate("/tags/"+entry.Key, entry.Posts))
+		}
+	}
+
+	return sitemap
+}
+
+// Render writes the sitemap as XML to the provided writer.
+func (sm *Sitemap) Render(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, `<?xml version="1.0" encoding="UTF-8" ?>`); err != nil {
+		return fmt.Errorf("sitemap: render: %w", err)
+	}
+
+	bytes, err := xml.MarshalIndent(sm, "", "  ")
+	if err != nil {
+		return fmt.Errorf("sitemap: render: %w", err)
+	}
+
+	if _, err := w.Write(bytes); err != nil {
+		return fmt.Errorf("sitemap: render: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package site_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/site"
+)
+
+func TestSitemap_Render(t *testing.T) {
+	s, err := site.New("testdata", site.SiteOptions{NotesExperiment: true})
+	assert.OK(t, err).Fatal()
+
+	post := s.Posts.GetBySlug("advanced-go-patterns")
+	post.Frontmatter.Updated = time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	err = s.Sitemap().Render(&buf)
+	assert.OK(t, err).Fatal()
+
+	var parsed struct {
+		URLs []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	err = xml.Unmarshal(buf.Bytes(), &parsed)
+	assert.OK(t, err).Fatal()
+
+	lastMod := map[string]string{}
+	for _, u := range parsed.URLs {
+		lastMod[u.Loc] = u.LastMod
+	}
+
+	base := "https://blog.ryanchaley.com"
+	assert.Equal(t, "updated post", "2025-12-01T12:00:00Z", lastMod[base+"/posts/advanced-go-patterns"])
+	assert.Equal(t, "post", "2025-09-20T10:00:00Z", lastMod[base+"/posts/getting-started-with-go"])
+	assert.Equal(t, "series post", "2024-02-01T00:00:00Z", lastMod[base+"/posts/go-basics/functions"])
+	assert.Equal(t, "home page", "2025-12-01T12:00:00Z", lastMod[base+"/"])
+	assert.Equal(t, "series index", "2024-02-01T00:00:00Z", lastMod[base+"/go-basics"])
+
+	_, ok := lastMod[base+"/about"]
+	assert.True(t, "about page", ok)
+	_, ok = lastMod[base+"/notes/algorithms"]
+	assert.True(t, "note page", ok)
+	_, ok = lastMod[base+"/posts/draft-exploring-go-generics"]
+	assert.False(t, "draft", ok)
+}
+
+func TestSitemap_Pagination(t *testing.T) {
+	s, err := site.New("testdata", site.SiteOptions{})
+	assert.OK(t, err).Fatal()
+	s.Config.PageSize = 2
+
+	var buf bytes.Buffer
+	err = s.Sitemap().Render(&buf)
+	assert.OK(t, err).Fatal()
+
+	sitemap := buf.String()
+	base := "https://blog.ryanchaley.com"
+	assert.True(t, "home page 2", strings.Contains(sitemap, "<loc>"+base+"/page/2</loc>"))
+	assert.True(t, "home page 3", strings.Contains(sitemap, "<loc>"+base+"/page/3</loc>"))
+	assert.False(t, "home page 1", strings.Contains(sitemap, "<loc>"+base+"/page/1</loc>"))
+	assert.True(t, "archive page 2", strings.Contains(sitemap, "<loc>"+base+"/archive/2025/page/2</loc>"))
+}
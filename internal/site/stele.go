@@ -12,16 +12,22 @@ type Renderer interface {
 	Render404(ctx context.Context, w io.Writer, site *Site) error
 	RenderAbout(ctx context.Context, w io.Writer, site *Site, about *About) error
 	RenderArchiveIndex(ctx context.Context, w io.Writer, site *Site) error
-	RenderArchivePage(ctx context.Context, w io.Writer, site *Site, year string, posts Posts) error
-	RenderIndex(ctx context.Context, w io.Writer, site *Site) error
+	RenderArchivePage(ctx context.Context, w io.Writer, site *Site, year string, page Page) error
+	RenderIndex(ctx context.Context, w io.Writer, site *Site, page Page) error
 	RenderManifest(ctx context.Context, w io.Writer, site *Site, manifest *Manifest) error
 	RenderNote(ctx context.Context, w io.Writer, site *Site, note *Note) error
+	RenderNoteGraph(ctx context.Context, w io.Writer, site *Site, graph *NoteGraph) error
+	RenderNoteGraphPage(ctx context.Context, w io.Writer, site *Site) error
+	RenderNoteHistory(ctx context.Context, w io.Writer, site *Site, note *Note) error
 	RenderNotesIndex(ctx context.Context, w io.Writer, site *Site) error
 	RenderNoteTagIndex(ctx context.Context, w io.Writer, site *Site) error
 	RenderNoteTagPage(ctx context.Context, w io.Writer, site *Site, tag string, notes Notes) error
 	RenderPost(ctx context.Context, w io.Writer, site *Site, post *Post) error
 	RenderRSSFeed(ctx context.Context, w io.Writer, site *Site, feed *RSSFeed) error
+	RenderSearchIndex(ctx context.Context, w io.Writer, site *Site, index *SearchIndex) error
+	RenderSearchPage(ctx context.Context, w io.Writer, site *Site) error
 	RenderSeriesIndex(ctx context.Context, w io.Writer, site *Site, series *Series) error
+	RenderSitemap(ctx context.Context, w io.Writer, site *Site, sitemap *Sitemap) error
 	RenderTagIndex(ctx context.Context, w io.Writer, site *Site) error
-	RenderTagPage(ctx context.Context, w io.Writer, site *Site, tag string, posts Posts) error
+	RenderTagPage(ctx context.Context, w io.Writer, site *Site, tag string, page Page) error
 }
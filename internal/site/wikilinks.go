@@ -0,0 +1,148 @@
+package site
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haleyrc/stele/internal/htmlutil"
+	"github.com/haleyrc/stele/internal/markdown"
+)
+
+// linker renders the wiki links and embeds in a site's notes and posts.
+type linker struct {
+	site *Site
+
+	// Includes snippets in the notes and posts as they're rendered.
+	includes markdown.Includes
+
+	// Renders diagrams in the notes and posts as they're rendered.
+	diagrams markdown.Diagrams
+
+	// The notes and posts linking to each note.
+	backlinks map[*Note][]Backlink
+
+	// Notes that have already been linked. Notes can be linked early when
+	// they are embedded in another note.
+	done map[*Note]bool
+}
+
+// linkNote renders the wiki links and embeds in note if that hasn't been done
+// yet. The stack holds the notes currently being linked, which embed each
+// other in order, and is used to detect cycles.
+func (l *linker) linkNote(note *Note, stack []*Note) error {
+	if l.done[note] {
+		return nil
+	}
+
+	for i, other := range stack {
+		if other == note {
+			var slugs []string
+			for _, n := range stack[i:] {
+				slugs = append(slugs, n.Slug)
+			}
+			return fmt.Errorf("embed cycle: %s -> %s", strings.Join(slugs, " -> "), note.Slug)
+		}
+	}
+
+	if note.doc != nil {
+		result, err := l.link(note.doc, note.Path, Backlink{Title: note.Frontmatter.Title, URL: "/notes/" + note.Slug}, append(stack, note))
+		if err != nil {
+			return err
+		}
+		note.Content, note.UnresolvedLinks = result.content, result.unresolved
+		for _, other := range result.notes {
+			note.Links = append(note.Links, other.Slug)
+		}
+		note.doc = nil
+	}
+
+	l.done[note] = true
+	return nil
+}
+
+// linked is the result of rendering a file with wiki links and embeds
+// resolved.
+type linked struct {
+	// The rendered content.
+	content string
+
+	// The targets of links and embeds that could not be resolved.
+	unresolved []string
+
+	// The notes linked to, in order of first appearance, excluding the file
+	// itself.
+	notes []*Note
+}
+
+// link renders doc, parsed from the file at path, with wiki links and embeds
+// resolved, snippets included, and diagrams rendered.
+func (l *linker) link(doc *markdown.Document, path string, from Backlink, stack []*Note) (*linked, error) {
+	embed := func(target, heading string) (string, bool, error) {
+		note := l.site.Notes.GetBySlug(target)
+		if note == nil {
+			return "", false, nil
+		}
+
+		if err := l.linkNote(note, stack); err != nil {
+			return "", false, err
+		}
+
+		content := note.Content
+		if heading != "" {
+			section, found := htmlutil.Section(content, heading)
+			if !found {
+				return "", false, nil
+			}
+			content = section
+		}
+
+		return htmlutil.PrefixIDs(content, "embed-"+note.Slug+"-"), true, nil
+	}
+
+	var content strings.Builder
+	var links []markdown.WikiLink
+	err := doc.RenderWith(&content, markdown.RenderOptions{
+		WikiLinks: markdown.WikiLinks{
+			Resolver: l.site.resolveWikiLink,
+			Embedder: embed,
+			OnLink:   func(link markdown.WikiLink) { links = append(links, link) },
+		},
+		Includes: l.includes,
+		Diagrams: l.diagrams,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &linked{content: content.String()}
+	seen := map[*Note]bool{}
+	for _, link := range links {
+		if !link.Resolved {
+			target := link.Target
+			if link.Embed && link.Fragment != "" {
+				target += "#" + link.Fragment
+			}
+			result.unresolved = append(result.unresolved, target)
+			continue
+		}
+		if note := l.site.Notes.GetBySlug(link.Target); note != nil && note.Path != path && !seen[note] {
+			seen[note] = true
+			result.notes = append(result.notes, note)
+			l.backlinks[note] = append(l.backlinks[note], from)
+		}
+	}
+
+	return result, nil
+}
+
+// resolveWikiLink looks up the note or post with the given slug. Notes take
+// precedence over posts with the same slug.
+func (s *Site) resolveWikiLink(target string) (string, string, bool) {
+	if note := s.Notes.GetBySlug(target); note != nil {
+		return "/notes/" + note.Slug, note.Frontmatter.Title, true
+	}
+	if post := s.Posts.GetBySlug(target); post != nil {
+		return "/posts/" + post.Slug, post.Frontmatter.Title, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,284 @@
+// Package snippet includes code from the files of a site in its content.
+//
+// The markdown package renders each include directive in a document as a code
+// block with the code an Includer loads for it. A directive can include a
+// whole file, a region of a file between marker comments:
+//
+//	// region: handler
+//	func handle(w http.ResponseWriter, r *http.Request) {}
+//	// endregion: handler
+//
+// or a Go function or method by name, along with its doc comment. Marker
+// comments are left out of the included code, so regions can overlap and be
+// nested.
+package snippet
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// markerPattern matches a line made up of a marker comment that starts or
+	// ends a region.
+	markerPattern = regexp.MustCompile(`^\s*(?://|#|--|;|/\*|<!--)\s*(end)?region:\s*([\w.-]+)\s*(?:\*/|-->)?\s*$`)
+)
+
+// languages maps file extensions to the language of the code in the file,
+// for extensions that aren't the name of the language.
+var languages = map[string]string{
+	".js":  "javascript",
+	".md":  "markdown",
+	".py":  "python",
+	".rb":  "ruby",
+	".rs":  "rust",
+	".sh":  "bash",
+	".ts":  "typescript",
+	".yml": "yaml",
+}
+
+// Snippet describes the code included by an include directive.
+type Snippet struct {
+	// The path of the file, relative to the site.
+	Path string
+
+	// The name of the region of the file to include. Empty to include the
+	// whole file, unless Func is set.
+	Region string
+
+	// The name of the Go function to include, or the type and name of the
+	// method separated by a dot e.g. "Server.ServeHTTP".
+	Func string
+
+	// The language of the code. Empty to use the extension of the file.
+	Lang string
+}
+
+// Includer loads the code selected by include directives.
+type Includer struct {
+	// The directory the paths of included files are relative to.
+	Root string
+
+	files []string
+	seen  map[string]bool
+	count int
+}
+
+// NewIncluder creates a new Includer for files in root.
+func NewIncluder(root string) *Includer {
+	return &Includer{
+		Root: root,
+		seen: map[string]bool{},
+	}
+}
+
+// Count returns the number of snippets included so far.
+func (i *Includer) Count() int {
+	return i.count
+}
+
+// Files returns the path of each file included so far, joined with the root,
+// in the order they were first included.
+func (i *Includer) Files() []string {
+	return i.files
+}
+
+// Load returns the code selected by snippet.
+func (i *Includer) Load(snippet Snippet) (string, error) {
+	rel := filepath.Clean(filepath.FromSlash(snippet.Path))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("include %q: path is outside the site", snippet.Path)
+	}
+	path := filepath.Join(i.Root, rel)
+
+	// The file is recorded before it's read so that Files includes a file
+	// that doesn't exist yet.
+	if !i.seen[path] {
+		i.seen[path] = true
+		i.files = append(i.files, path)
+	}
+
+	src, err := os.ReadFile(path) // #nosec G304 - Included files are chosen by the site author
+	if err != nil {
+		return "", fmt.Errorf("include %q: %w", snippet.Path, err)
+	}
+
+	var lines []string
+	switch {
+	case snippet.Func != "":
+		lines, err = extractFunc(path, src, snippet.Func)
+	case snippet.Region != "":
+		lines, err = extractRegion(src, snippet.Region)
+	default:
+		lines = splitLines(src)
+	}
+	if err != nil {
+		return "", fmt.Errorf("include %q: %w", snippet.Path, err)
+	}
+
+	i.count++
+	return strings.Join(dedent(stripMarkers(lines)), "\n") + "\n", nil
+}
+
+// Language returns the language of the code in the snippet: Lang if it's set,
+// or the language of the file going by its extension.
+func (s Snippet) Language() string {
+	if s.Lang != "" {
+		return s.Lang
+	}
+	ext := strings.ToLower(filepath.Ext(s.Path))
+	if lang, ok := languages[ext]; ok {
+		return lang
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// extractRegion returns the lines of src between the marker comments for the
+// region called name.
+func extractRegion(src []byte, name string) ([]string, error) {
+	lines := splitLines(src)
+
+	start := -1
+	for n, line := range lines {
+		m := markerPattern.FindStringSubmatch(line)
+		if m == nil || m[2] != name {
+			continue
+		}
+		isEnd := m[1] != ""
+		if start < 0 && !isEnd {
+			start = n + 1
+		} else if start >= 0 && isEnd {
+			return lines[start:n], nil
+		}
+	}
+
+	if start < 0 {
+		return nil, fmt.Errorf("no region %q", name)
+	}
+	return nil, fmt.Errorf("region %q starting on line %d is never closed", name, start)
+}
+
+// extractFunc returns the lines of the Go function or method called name in
+// src, including its doc comment.
+func extractFunc(path string, src []byte, name string) ([]string, error) {
+	if filepath.Ext(path) != ".go" {
+		return nil, fmt.Errorf("func %q: not a Go file", name)
+	}
+
+	recv, fn, isMethod := strings.Cut(name, ".")
+	if !isMethod {
+		recv, fn = "", name
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("func %q: %w", name, err)
+	}
+
+	for _, decl := range file.Decls {
+		f, ok := decl.(*ast.FuncDecl)
+		if !ok || f.Name.Name != fn || receiverName(f) != recv {
+			continue
+		}
+
+		start := f.Pos()
+		if f.Doc != nil {
+			start = f.Doc.Pos()
+		}
+		// Start at the beginning of the line to keep any indentation.
+		offset := fset.Position(start).Offset
+		offset = bytes.LastIndexByte(src[:offset], '\n') + 1
+		return splitLines(src[offset:fset.Position(f.End()).Offset]), nil
+	}
+
+	if isMethod {
+		return nil, fmt.Errorf("no method %q", name)
+	}
+	return nil, fmt.Errorf("no function %q", name)
+}
+
+// receiverName returns the name of the type of the receiver of f, or an empty
+// string if f isn't a method.
+func receiverName(f *ast.FuncDecl) string {
+	if f.Recv == nil || len(f.Recv.List) == 0 {
+		return ""
+	}
+
+	typ := f.Recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.ParenExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// splitLines splits src into lines without their line endings.
+func splitLines(src []byte) []string {
+	s := strings.ReplaceAll(string(src), "\r\n", "\n")
+	s = strings.TrimSuffix(s, "\n")
+	return strings.Split(s, "\n")
+}
+
+// stripMarkers returns lines without any marker comments.
+func stripMarkers(lines []string) []string {
+	var stripped []string
+	for _, line := range lines {
+		if markerPattern.MatchString(line) {
+			continue
+		}
+		stripped = append(stripped, line)
+	}
+	return stripped
+}
+
+// dedent removes the indentation shared by all of the non-blank lines, along
+// with any blank lines at the start and end.
+func dedent(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var prefix string
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	dedented := make([]string, len(lines))
+	for n, line := range lines {
+		dedented[n] = strings.TrimPrefix(line, prefix)
+	}
+	return dedented
+}
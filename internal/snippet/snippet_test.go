@@ -0,0 +1,156 @@
+package snippet_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/snippet"
+)
+
+const server = `package main
+
+import "net/http"
+
+// region: main
+func main() {
+	// region: handler
+	http.HandleFunc("/", handle)
+	// endregion: handler
+	http.ListenAndServe(":8080", nil)
+}
+// endregion: main
+
+// handle says hello.
+func handle(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("<hello>"))
+}
+
+type Server struct{}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
+`
+
+func newIncluder(t *testing.T) *snippet.Includer {
+	root := t.TempDir()
+	err := os.MkdirAll(filepath.Join(root, "examples"), 0750)
+	assert.OK(t, err).Fatal()
+	err = os.WriteFile(filepath.Join(root, "examples", "main.go"), []byte(server), 0600)
+	assert.OK(t, err).Fatal()
+	return snippet.NewIncluder(root)
+}
+
+func TestIncluder_Load(t *testing.T) {
+	testCases := map[string]struct {
+		snippet snippet.Snippet
+		want    string
+	}{
+		"region": {
+			snippet: snippet.Snippet{Path: "examples/main.go", Region: "handler"},
+			want:    "http.HandleFunc(\"/\", handle)\n",
+		},
+		"nested region": {
+			snippet: snippet.Snippet{Path: "examples/main.go", Region: "main"},
+			want:    "func main() {\n\thttp.HandleFunc(\"/\", handle)\n\thttp.ListenAndServe(\":8080\", nil)\n}\n",
+		},
+		"func": {
+			snippet: snippet.Snippet{Path: "examples/main.go", Func: "handle"},
+			want:    "// handle says hello.\nfunc handle(w http.ResponseWriter, r *http.Request) {\n\tw.Write([]byte(\"<hello>\"))\n}\n",
+		},
+		"method": {
+			snippet: snippet.Snippet{Path: "examples/main.go", Func: "Server.ServeHTTP"},
+			want:    "func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {}\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := newIncluder(t).Load(tc.snippet)
+			assert.OK(t, err).Fatal()
+			assert.Equal(t, "code", tc.want, got)
+		})
+	}
+}
+
+func TestIncluder_Load_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		snippet snippet.Snippet
+		want    string
+	}{
+		"missing file": {
+			snippet: snippet.Snippet{Path: "examples/client.go"},
+			want:    `include "examples/client.go": open `,
+		},
+		"outside site": {
+			snippet: snippet.Snippet{Path: "../secrets.go"},
+			want:    `include "../secrets.go": path is outside the site`,
+		},
+		"missing region": {
+			snippet: snippet.Snippet{Path: "examples/main.go", Region: "client"},
+			want:    `include "examples/main.go": no region "client"`,
+		},
+		"missing func": {
+			snippet: snippet.Snippet{Path: "examples/main.go", Func: "serve"},
+			want:    `include "examples/main.go": no function "serve"`,
+		},
+		"missing method": {
+			snippet: snippet.Snippet{Path: "examples/main.go", Func: "Client.Do"},
+			want:    `include "examples/main.go": no method "Client.Do"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := newIncluder(t).Load(tc.snippet)
+			assert.Error(t, err, tc.want)
+		})
+	}
+}
+
+func TestIncluder_Files(t *testing.T) {
+	inc := newIncluder(t)
+
+	_, err := inc.Load(snippet.Snippet{Path: "examples/main.go", Func: "handle"})
+	assert.OK(t, err).Fatal()
+	_, err = inc.Load(snippet.Snippet{Path: "examples/main.go", Region: "client"})
+	assert.Error(t, err, `include "examples/main.go": no region "client"`)
+	_, err = inc.Load(snippet.Snippet{Path: "examples/missing.go"})
+	assert.Error(t, err, `include "examples/missing.go"`)
+
+	assert.Equal(t, "count", 1, inc.Count())
+	assert.SliceEqual(t, "files", []string{
+		filepath.Join(inc.Root, "examples", "main.go"),
+		filepath.Join(inc.Root, "examples", "missing.go"),
+	}, inc.Files())
+}
+
+func TestSnippet_Language(t *testing.T) {
+	testCases := map[string]struct {
+		snippet snippet.Snippet
+		want    string
+	}{
+		"extension": {
+			snippet: snippet.Snippet{Path: "main.go"},
+			want:    "go",
+		},
+		"mapped": {
+			snippet: snippet.Snippet{Path: "app.TS"},
+			want:    "typescript",
+		},
+		"override": {
+			snippet: snippet.Snippet{Path: "main.go", Lang: "text"},
+			want:    "text",
+		},
+		"no extension": {
+			snippet: snippet.Snippet{Path: "Makefile"},
+			want:    "",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, "language", tc.want, tc.snippet.Language())
+		})
+	}
+}
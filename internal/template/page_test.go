@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/haleyrc/assert"
+	"github.com/haleyrc/stele/internal/diff"
 	"github.com/haleyrc/stele/internal/site"
 	"github.com/haleyrc/stele/internal/template"
 )
@@ -15,7 +16,7 @@ func TestTemplateRenderer_RenderIndex(t *testing.T) {
 	renderer := template.NewTemplateRenderer()
 
 	var buf bytes.Buffer
-	err := renderer.RenderIndex(context.Background(), &buf, s)
+	err := renderer.RenderIndex(context.Background(), &buf, s, s.HomePages()[0])
 	assert.OK(t, err).Fatal()
 
 	compareGolden(t, buf.String(), "testdata/golden/page_homepage.html")
@@ -46,12 +47,32 @@ func TestTemplateRenderer_RenderIndex_WithSeries(t *testing.T) {
 	renderer := template.NewTemplateRenderer()
 
 	var buf bytes.Buffer
-	err := renderer.RenderIndex(context.Background(), &buf, s)
+	err := renderer.RenderIndex(context.Background(), &buf, s, s.HomePages()[0])
 	assert.OK(t, err).Fatal()
 
 	compareGolden(t, buf.String(), "testdata/golden/page_homepage_with_series.html")
 }
 
+func TestTemplateRenderer_RenderTagPage_Paginated(t *testing.T) {
+	s := newTestSite()
+	s.Config.PageSize = 1
+	s.Posts = site.Posts{
+		newTestPost("third", "Third Post", "2024-03-01"),
+		newTestPost("second", "Second Post", "2024-02-01"),
+		newTestPost("first", "First Post", "2024-01-01"),
+	}
+	renderer := template.NewTemplateRenderer()
+
+	pages := s.Paginate("/tags/go", s.Posts)
+	assert.Equal(t, "page count", 3, len(pages))
+
+	var buf bytes.Buffer
+	err := renderer.RenderTagPage(context.Background(), &buf, s, "go", pages[1])
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/page_tag_paginated.html")
+}
+
 func TestTemplateRenderer_RenderTagIndex(t *testing.T) {
 	s := newTestSite()
 	renderer := template.NewTemplateRenderer()
@@ -73,3 +94,130 @@ func TestTemplateRenderer_RenderArchiveIndex(t *testing.T) {
 
 	compareGolden(t, buf.String(), "testdata/golden/page_archive_index.html")
 }
+
+func TestTemplateRenderer_RenderNotesIndex_RecentlyUpdated(t *testing.T) {
+	s := newTestSite()
+	s.Notes = site.Notes{
+		{
+			Slug:        "algorithms",
+			Frontmatter: site.NoteFrontmatter{Title: "Algorithms", Tags: []string{}},
+			Modified:    parseDate("2024-03-01"),
+		},
+		{
+			Slug:        "vim",
+			Frontmatter: site.NoteFrontmatter{Title: "Vim", Tags: []string{}, Pinned: true},
+			Modified:    parseDate("2024-04-01"),
+		},
+		{
+			Slug:        "uncommitted",
+			Frontmatter: site.NoteFrontmatter{Title: "Uncommitted", Tags: []string{}},
+		},
+	}
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderNotesIndex(context.Background(), &buf, s)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/page_notes_recently_updated.html")
+}
+
+func TestTemplateRenderer_RenderNote_LastUpdated(t *testing.T) {
+	s := newTestSite()
+	note := &site.Note{
+		Slug:        "vim",
+		Frontmatter: site.NoteFrontmatter{Title: "Vim", Tags: []string{"tools"}},
+		Content:     "<p>Use :wq to save and quit.</p>",
+		Modified:    parseDate("2024-04-01"),
+	}
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderNote(context.Background(), &buf, s, note)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/note_last_updated.html")
+}
+
+func TestTemplateRenderer_RenderNoteHistory(t *testing.T) {
+	s := newTestSite()
+	note := &site.Note{
+		Slug:        "vim",
+		Frontmatter: site.NoteFrontmatter{Title: "Vim", Tags: []string{}, History: true},
+		Revisions: []site.NoteRevision{
+			{
+				Date:    parseDate("2024-04-01"),
+				Subject: "Explain quitting",
+				Diff:    diff.Lines("# Vim\n\nUse :w to save.\n", "# Vim\n\nUse :w to save.\nUse :wq to save and quit.\n"),
+			},
+			{
+				Date:    parseDate("2024-03-01"),
+				Subject: "Add vim note",
+				Diff:    diff.Lines("", "# Vim\n\nUse :w to save.\n"),
+			},
+		},
+	}
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderNoteHistory(context.Background(), &buf, s, note)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/note_history.html")
+}
+
+func TestTemplateRenderer_RenderNote_Backlinks(t *testing.T) {
+	s := newTestSite()
+	note := &site.Note{
+		Slug:        "editors",
+		Frontmatter: site.NoteFrontmatter{Title: "Editors", Tags: []string{}},
+		Content:     `<p>See <a class="wikilink" href="/notes/vim">Vim</a>.</p>`,
+		Backlinks: []site.Backlink{
+			{Title: "Hello", URL: "/posts/hello"},
+			{Title: "Vim", URL: "/notes/vim"},
+		},
+	}
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderNote(context.Background(), &buf, s, note)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/note_backlinks.html")
+}
+
+func TestTemplateRenderer_RenderNote_Breadcrumbs(t *testing.T) {
+	s := newTestSite()
+	note := &site.Note{
+		Slug:        "go/concurrency/channels",
+		Frontmatter: site.NoteFrontmatter{Title: "Channels", Tags: []string{}},
+		Content:     "<p>Don't communicate by sharing memory.</p>",
+		Breadcrumbs: []site.Breadcrumb{
+			{Title: "Go", URL: "/notes/go"},
+			{Title: "concurrency"},
+		},
+	}
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderNote(context.Background(), &buf, s, note)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/note_breadcrumbs.html")
+}
+
+func TestTemplateRenderer_RenderNotesIndex_Tree(t *testing.T) {
+	s := newTestSite()
+	s.Notes = site.Notes{
+		{Slug: "vim", Frontmatter: site.NoteFrontmatter{Title: "Vim", Tags: []string{}}},
+		{Slug: "go", Frontmatter: site.NoteFrontmatter{Title: "Go", Tags: []string{}}},
+		{Slug: "go/concurrency/channels", Frontmatter: site.NoteFrontmatter{Title: "Channels", Tags: []string{}}},
+	}
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderNotesIndex(context.Background(), &buf, s)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/page_notes_tree.html")
+}
@@ -0,0 +1,42 @@
+// Code generated by templ - DO NOT EDIT.
+
+package pages
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+// NoteGraph renders a page visualizing the notes in a site as a graph. The
+// graph is loaded from /notes/graph.json and laid out in the browser with a
+// simple force-directed layout. Clicking a note or tag navigates to it.
+func NoteGraph() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<article><h1 class=\"text-2xl font-light pb-2\">Note Graph</h1><p class=\"text-sm font-extralight pb-4\">Solid lines are links between notes. Dashed lines connect notes to their tags.</p><div id=\"note-graph\" class=\"border rounded w-full h-[32rem]\"></div><script>\n\t\t\t(async function () {\n\t\t\t\tconst svgNS = \"http://www.w3.org/2000/svg\";\n\t\t\t\tconst container = document.getElementById(\"note-graph\");\n\t\t\t\tconst graph = await (await fetch(\"/notes/graph.json\")).json();\n\n\t\t\t\tconst width = container.clientWidth;\n\t\t\t\tconst height = container.clientHeight;\n\t\t\t\tconst margin = 20;\n\t\t\t\tconst k = Math.sqrt((width * height) / Math.max(graph.nodes.length, 1));\n\n\t\t\t\t// Start with the nodes spread around a circle so that no two\n\t\t\t\t// share a position.\n\t\t\t\tconst nodes = new Map();\n\t\t\t\tgraph.nodes.forEach(function (node, i) {\n\t\t\t\t\tconst angle = (2 * Math.PI * i) / graph.nodes.length;\n\t\t\t\t\tnodes.set(node.id, {\n\t\t\t\t\t\t...node,\n\t\t\t\t\t\tx: width / 2 + (width / 4) * Math.cos(angle),\n\t\t\t\t\t\ty: height / 2 + (height / 4) * Math.sin(angle),\n\t\t\t\t\t});\n\t\t\t\t});\n\t\t\t\tconst edges = graph.edges.filter(function (edge) {\n\t\t\t\t\treturn nodes.has(edge.source) && nodes.has(edge.target);\n\t\t\t\t});\n\n\t\t\t\t// Fruchterman-Reingold: nearby nodes repel, edges attract, and\n\t\t\t\t// movement is limited by a cooling temperature. Only nodes in\n\t\t\t\t// the same or neighboring cells of a grid repel, so each step\n\t\t\t\t// doesn't have to compare every pair of nodes.\n\t\t\t\tconst cell = 2 * k;\n\t\t\t\tlet temperature = width / 10;\n\t\t\t\tfor (let i = 0; i < 300; i++) {\n\t\t\t\t\tconst grid = new Map();\n\t\t\t\t\tfor (const node of nodes.values()) {\n\t\t\t\t\t\tnode.dx = 0;\n\t\t\t\t\t\tnode.dy = 0;\n\t\t\t\t\t\tconst key = Math.floor(node.x / cell) + \",\" + Math.floor(node.y / cell);\n\t\t\t\t\t\tif (!grid.has(key)) {\n\t\t\t\t\t\t\tgrid.set(key, []);\n\t\t\t\t\t\t}\n\t\t\t\t\t\tgrid.get(key).push(node);\n\t\t\t\t\t}\n\t\t\t\t\tfor (const a of nodes.values()) {\n\t\t\t\t\t\tconst cx = Math.floor(a.x / cell);\n\t\t\t\t\t\tconst cy = Math.floor(a.y / cell);\n\t\t\t\t\t\tfor (let gx = cx - 1; gx <= cx + 1; gx++) {\n\t\t\t\t\t\t\tfor (let gy = cy - 1; gy <= cy + 1; gy++) {\n\t\t\t\t\t\t\t\tfor (const b of grid.get(gx + \",\" + gy) || []) {\n\t\t\t\t\t\t\t\t\tif (a === b) {\n\t\t\t\t\t\t\t\t\t\tcontinue;\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t\tconst dx = a.x - b.x;\n\t\t\t\t\t\t\t\t\tconst dy = a.y - b.y;\n\t\t\t\t\t\t\t\t\tconst d = Math.max(Math.hypot(dx, dy), 0.01);\n\t\t\t\t\t\t\t\t\tif (d > cell) {\n\t\t\t\t\t\t\t\t\t\tcontinue;\n\t\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t\t\ta.dx += (dx / d) * ((k * k) / d);\n\t\t\t\t\t\t\t\t\ta.dy += (dy / d) * ((k * k) / d);\n\t\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t\tfor (const edge of edges) {\n\t\t\t\t\t\tconst a = nodes.get(edge.source);\n\t\t\t\t\t\tconst b = nodes.get(edge.target);\n\t\t\t\t\t\tconst dx = a.x - b.x;\n\t\t\t\t\t\tconst dy = a.y - b.y;\n\t\t\t\t\t\tconst d = Math.max(Math.hypot(dx, dy), 0.01);\n\t\t\t\t\t\tconst f = (d * d) / k;\n\t\t\t\t\t\ta.dx -= (dx / d) * f;\n\t\t\t\t\t\ta.dy -= (dy / d) * f;\n\t\t\t\t\t\tb.dx += (dx / d) * f;\n\t\t\t\t\t\tb.dy += (dy / d) * f;\n\t\t\t\t\t}\n\t\t\t\t\tfor (const node of nodes.values()) {\n\t\t\t\t\t\tconst d = Math.max(Math.hypot(node.dx, node.dy), 0.01);\n\t\t\t\t\t\tnode.x += (node.dx / d) * Math.min(d, temperature);\n\t\t\t\t\t\tnode.y += (node.dy / d) * Math.min(d, temperature);\n\t\t\t\t\t\tnode.x = Math.min(width - margin, Math.max(margin, node.x));\n\t\t\t\t\t\tnode.y = Math.min(height - margin, Math.max(margin, node.y));\n\t\t\t\t\t}\n\t\t\t\t\ttemperature *= 0.98;\n\t\t\t\t}\n\n\t\t\t\tconst svg = document.createElementNS(svgNS, \"svg\");\n\t\t\t\tsvg.setAttribute(\"width\", width);\n\t\t\t\tsvg.setAttribute(\"height\", height);\n\n\t\t\t\tfor (const edge of edges) {\n\t\t\t\t\tconst a = nodes.get(edge.source);\n\t\t\t\t\tconst b = nodes.get(edge.target);\n\t\t\t\t\tconst line = document.createElementNS(svgNS, \"line\");\n\t\t\t\t\tline.setAttribute(\"x1\", a.x);\n\t\t\t\t\tline.setAttribute(\"y1\", a.y);\n\t\t\t\t\tline.setAttribute(\"x2\", b.x);\n\t\t\t\t\tline.setAttribute(\"y2\", b.y);\n\t\t\t\t\tif (edge.type === \"tag\") {\n\t\t\t\t\t\tline.setAttribute(\"stroke\", \"#d1d5db\");\n\t\t\t\t\t\tline.setAttribute(\"stroke-dasharray\", \"4 4\");\n\t\t\t\t\t} else {\n\t\t\t\t\t\tline.setAttribute(\"stroke\", \"#6b7280\");\n\t\t\t\t\t}\n\t\t\t\t\tsvg.appendChild(line);\n\t\t\t\t}\n\n\t\t\t\tfor (const node of nodes.values()) {\n\t\t\t\t\tconst link = document.createElementNS(svgNS, \"a\");\n\t\t\t\t\tlink.setAttribute(\"href\", node.url);\n\n\t\t\t\t\tconst title = document.createElementNS(svgNS, \"title\");\n\t\t\t\t\ttitle.textContent = node.title;\n\t\t\t\t\tlink.appendChild(title);\n\n\t\t\t\t\tconst isTag = node.type === \"tag\";\n\t\t\t\t\tconst circle = document.createElementNS(svgNS, \"circle\");\n\t\t\t\t\tcircle.setAttribute(\"cx\", node.x);\n\t\t\t\t\tcircle.setAttribute(\"cy\", node.y);\n\t\t\t\t\tcircle.setAttribute(\"r\", isTag ? 4 : 6);\n\t\t\t\t\tcircle.setAttribute(\"fill\", isTag ? \"#9ca3af\" : \"#3b82f6\");\n\t\t\t\t\tlink.appendChild(circle);\n\n\t\t\t\t\tconst label = document.createElementNS(svgNS, \"text\");\n\t\t\t\t\tlabel.setAttribute(\"x\", node.x + 9);\n\t\t\t\t\tlabel.setAttribute(\"y\", node.y + 4);\n\t\t\t\t\tlabel.setAttribute(\"font-size\", 12);\n\t\t\t\t\tif (isTag) {\n\t\t\t\t\t\tlabel.setAttribute(\"fill\", \"#6b7280\");\n\t\t\t\t\t}\n\t\t\t\t\tlabel.textContent = isTag ? \"#\" + node.title : node.title;\n\t\t\t\t\tlink.appendChild(label);\n\n\t\t\t\t\tsvg.appendChild(link);\n\t\t\t\t}\n\n\t\t\t\tcontainer.appendChild(svg);\n\t\t\t})();\n\t\t</script></article>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
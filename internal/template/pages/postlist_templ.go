@@ -12,9 +12,9 @@ import (
 	"github.com/haleyrc/stele/internal/template/components"
 )
 
-// PostList renders a page containing a list of posts that share a common key
-// e.g. posts for a specific year or tag.
-func PostList(heading string, posts site.Posts) templ.Component {
+// PostList renders a page of a list of posts that share a common key e.g.
+// posts for a specific year or tag.
+func PostList(heading string, page site.Page) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -52,7 +52,11 @@ func PostList(heading string, posts site.Posts) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = components.PostList(posts).Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = components.PostList(page.Posts).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.Pagination(page).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
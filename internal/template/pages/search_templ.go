@@ -0,0 +1,44 @@
+// Code generated by templ - DO NOT EDIT.
+
+package pages
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+// Search renders the search page. The search index is loaded from
+// /search.json and queried in the browser as the user types, matching the
+// prefixes of words and ranking matches in titles above matches in tags,
+// headings, descriptions, and body text. The query is kept in the q parameter
+// of the URL so searches can be linked to.
+func Search() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<article><h1 class=\"text-2xl font-light pb-4\">Search</h1><form id=\"search-form\" action=\"/search\" role=\"search\" class=\"pb-4\"><input id=\"search-input\" type=\"search\" name=\"q\" placeholder=\"Search posts and notes\" autocomplete=\"off\" class=\"w-full border rounded px-2 py-1\"></form><ul id=\"search-results\"></ul><script>\n\t\t\t(async function () {\n\t\t\t\tconst form = document.getElementById(\"search-form\");\n\t\t\t\tconst input = document.getElementById(\"search-input\");\n\t\t\t\tconst results = document.getElementById(\"search-results\");\n\t\t\t\tconst index = await (await fetch(\"/search.json\")).json();\n\t\t\t\tconst vocabulary = Object.keys(index.terms);\n\n\t\t\t\tfunction tokenize(text) {\n\t\t\t\t\treturn text.toLowerCase().split(/[^\\p{L}\\p{N}]+/u).filter(function (word) {\n\t\t\t\t\t\treturn word.length > 0;\n\t\t\t\t\t});\n\t\t\t\t}\n\n\t\t\t\tconst docs = index.documents.map(function (doc) {\n\t\t\t\t\treturn {\n\t\t\t\t\t\t...doc,\n\t\t\t\t\t\ttitleWords: tokenize(doc.title),\n\t\t\t\t\t\ttagWords: tokenize((doc.tags || []).join(\" \")),\n\t\t\t\t\t\theadingWords: tokenize((doc.headings || []).join(\" \")),\n\t\t\t\t\t\tdescriptionWords: tokenize(doc.description || \"\"),\n\t\t\t\t\t};\n\t\t\t\t});\n\n\t\t\t\tfunction matches(words, term) {\n\t\t\t\t\treturn words.some(function (word) {\n\t\t\t\t\t\treturn word.startsWith(term);\n\t\t\t\t\t});\n\t\t\t\t}\n\n\t\t\t\t// Returns the documents matching every term in the query,\n\t\t\t\t// best matches first.\n\t\t\t\tfunction search(query) {\n\t\t\t\t\tconst terms = tokenize(query);\n\t\t\t\t\tconst scores = docs.map(function () {\n\t\t\t\t\t\treturn 0;\n\t\t\t\t\t});\n\t\t\t\t\tconst matched = docs.map(function () {\n\t\t\t\t\t\treturn 0;\n\t\t\t\t\t});\n\n\t\t\t\t\tfor (const term of terms) {\n\t\t\t\t\t\tconst body = new Set();\n\t\t\t\t\t\tfor (const word of vocabulary) {\n\t\t\t\t\t\t\tif (word.startsWith(term)) {\n\t\t\t\t\t\t\t\tindex.terms[word].forEach(function (i) {\n\t\t\t\t\t\t\t\t\tbody.add(i);\n\t\t\t\t\t\t\t\t});\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t}\n\n\t\t\t\t\t\tdocs.forEach(function (doc, i) {\n\t\t\t\t\t\t\tlet score = 0;\n\t\t\t\t\t\t\tif (matches(doc.titleWords, term)) {\n\t\t\t\t\t\t\t\tscore += 10;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tif (matches(doc.tagWords, term)) {\n\t\t\t\t\t\t\t\tscore += 5;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tif (matches(doc.headingWords, term)) {\n\t\t\t\t\t\t\t\tscore += 3;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tif (matches(doc.descriptionWords, term)) {\n\t\t\t\t\t\t\t\tscore += 2;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tif (body.has(i)) {\n\t\t\t\t\t\t\t\tscore += 1;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t\tif (score > 0) {\n\t\t\t\t\t\t\t\tscores[i] += score;\n\t\t\t\t\t\t\t\tmatched[i]++;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t});\n\t\t\t\t\t}\n\n\t\t\t\t\treturn docs\n\t\t\t\t\t\t.map(function (doc, i) {\n\t\t\t\t\t\t\treturn { doc: doc, score: scores[i], matched: matched[i] };\n\t\t\t\t\t\t})\n\t\t\t\t\t\t.filter(function (result) {\n\t\t\t\t\t\t\treturn terms.length > 0 && result.matched === terms.length;\n\t\t\t\t\t\t})\n\t\t\t\t\t\t.sort(function (a, b) {\n\t\t\t\t\t\t\treturn b.score - a.score;\n\t\t\t\t\t\t})\n\t\t\t\t\t\t.map(function (result) {\n\t\t\t\t\t\t\treturn result.doc;\n\t\t\t\t\t\t});\n\t\t\t\t}\n\n\t\t\t\tfunction render(query) {\n\t\t\t\t\tresults.replaceChildren();\n\t\t\t\t\tif (query.trim() === \"\") {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\n\t\t\t\t\tconst found = search(query);\n\t\t\t\t\tif (found.length === 0) {\n\t\t\t\t\t\tconst empty = document.createElement(\"li\");\n\t\t\t\t\t\tempty.className = \"font-extralight\";\n\t\t\t\t\t\tempty.textContent = \"No results found.\";\n\t\t\t\t\t\tresults.appendChild(empty);\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\n\t\t\t\t\tfor (const doc of found.slice(0, 50)) {\n\t\t\t\t\t\tconst item = document.createElement(\"li\");\n\t\t\t\t\t\titem.className = \"pb-4\";\n\n\t\t\t\t\t\tconst link = document.createElement(\"a\");\n\t\t\t\t\t\tlink.className = \"hover:underline\";\n\t\t\t\t\t\tlink.href = doc.url;\n\t\t\t\t\t\tlink.textContent = doc.title;\n\t\t\t\t\t\titem.appendChild(link);\n\n\t\t\t\t\t\tconst type = document.createElement(\"span\");\n\t\t\t\t\t\ttype.className = \"pl-2 text-xs font-extralight\";\n\t\t\t\t\t\ttype.textContent = doc.type;\n\t\t\t\t\t\titem.appendChild(type);\n\n\t\t\t\t\t\tif (doc.description) {\n\t\t\t\t\t\t\tconst description = document.createElement(\"p\");\n\t\t\t\t\t\t\tdescription.className = \"text-sm font-extralight\";\n\t\t\t\t\t\t\tdescription.textContent = doc.description;\n\t\t\t\t\t\t\titem.appendChild(description);\n\t\t\t\t\t\t}\n\n\t\t\t\t\t\tresults.appendChild(item);\n\t\t\t\t\t}\n\t\t\t\t}\n\n\t\t\t\tinput.value = new URLSearchParams(window.location.search).get(\"q\") || \"\";\n\t\t\t\trender(input.value);\n\n\t\t\t\tform.addEventListener(\"submit\", function (event) {\n\t\t\t\t\tevent.preventDefault();\n\t\t\t\t});\n\t\t\t\tinput.addEventListener(\"input\", function () {\n\t\t\t\t\tconst url = new URL(window.location);\n\t\t\t\t\tif (input.value) {\n\t\t\t\t\t\turl.searchParams.set(\"q\", input.value);\n\t\t\t\t\t} else {\n\t\t\t\t\t\turl.searchParams.delete(\"q\");\n\t\t\t\t\t}\n\t\t\t\t\thistory.replaceState(null, \"\", url);\n\t\t\t\t\trender(input.value);\n\t\t\t\t});\n\t\t\t})();\n\t\t</script></article>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
@@ -97,3 +97,47 @@ func TestTemplateRenderer_RenderPost_SeriesLast(t *testing.T) {
 
 	compareGolden(t, buf.String(), "testdata/golden/post_series_last.html")
 }
+
+func TestTemplateRenderer_RenderPost_Scheduled(t *testing.T) {
+	s := newTestSite()
+	post := newTestPost("test-post", "Test Post", "2024-01-01")
+	post.Scheduled = true
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderPost(context.Background(), &buf, s, post)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/post_scheduled.html")
+}
+
+func TestTemplateRenderer_RenderPost_Updated(t *testing.T) {
+	s := newTestSite()
+	post := newTestPost("test-post", "Test Post", "2024-01-01")
+	post.Frontmatter.Updated = parseDate("2024-02-01")
+	post.Frontmatter.Changelog = []site.ChangelogEntry{
+		{Date: parseDate("2024-01-10"), Note: "Fixed a typo."},
+		{Date: parseDate("2024-02-01"), Note: "Added a section on testing."},
+	}
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderPost(context.Background(), &buf, s, post)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/post_updated.html")
+}
+
+func TestTemplateRenderer_RenderPost_Sidenotes(t *testing.T) {
+	s := newTestSite()
+	post := newTestPost("test-post", "Test Post", "2024-01-01")
+	post.Frontmatter.Sidenotes = true
+	post.Content = `<p>A claim.<label for="sn:1" class="sidenote-number">1</label><input type="checkbox" id="sn:1" class="sidenote-toggle"><span class="sidenote" role="note"><span class="sidenote-number">1</span> A source.</span></p>`
+	renderer := template.NewTemplateRenderer()
+
+	var buf bytes.Buffer
+	err := renderer.RenderPost(context.Background(), &buf, s, post)
+	assert.OK(t, err).Fatal()
+
+	compareGolden(t, buf.String(), "testdata/golden/post_sidenotes.html")
+}
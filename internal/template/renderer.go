@@ -17,11 +17,13 @@ func NewTemplateRenderer() *TemplateRenderer {
 	return &TemplateRenderer{}
 }
 
-// RenderIndex renders the index page using templ components.
-func (r *TemplateRenderer) RenderIndex(ctx context.Context, w io.Writer, site *site.Site) error {
-	recentPosts := site.Posts.Recent(11)
-	latestPost, remainingPosts := recentPosts.Head()
-	return Layout("Home Page", site, pages.Index(latestPost, remainingPosts)).Render(ctx, w)
+// RenderIndex renders a page of the index using templ components.
+func (r *TemplateRenderer) RenderIndex(ctx context.Context, w io.Writer, site *site.Site, page site.Page) error {
+	latestPost, remainingPosts := page.Posts.Head()
+	if page.Number > 1 {
+		latestPost, remainingPosts = nil, page.Posts
+	}
+	return PagedLayout(pageTitle("Home Page", page), site, page, pages.Index(latestPost, remainingPosts, page)).Render(ctx, w)
 }
 
 // RenderPost renders a single post page using templ components.
@@ -36,10 +38,10 @@ func (r *TemplateRenderer) RenderTagIndex(ctx context.Context, w io.Writer, site
 	return Layout("Tags", site, pages.PostIndex(postsByTag, "/tags/")).Render(ctx, w)
 }
 
-// RenderTagPage renders a page showing all posts with a specific tag.
-func (r *TemplateRenderer) RenderTagPage(ctx context.Context, w io.Writer, site *site.Site, tag string, posts site.Posts) error {
-	heading := fmt.Sprintf("Posts tagged %q", tag)
-	return Layout(heading, site, pages.PostList(heading, posts)).Render(ctx, w)
+// RenderTagPage renders a page of the posts with a specific tag.
+func (r *TemplateRenderer) RenderTagPage(ctx context.Context, w io.Writer, site *site.Site, tag string, page site.Page) error {
+	heading := pageTitle(fmt.Sprintf("Posts tagged %q", tag), page)
+	return PagedLayout(heading, site, page, pages.PostList(heading, page)).Render(ctx, w)
 }
 
 // RenderArchiveIndex renders the archive index page listing all years with post
@@ -49,10 +51,10 @@ func (r *TemplateRenderer) RenderArchiveIndex(ctx context.Context, w io.Writer,
 	return Layout("Archive", site, pages.PostIndex(postsByYear, "/archive/")).Render(ctx, w)
 }
 
-// RenderArchivePage renders a page showing all posts from a specific year.
-func (r *TemplateRenderer) RenderArchivePage(ctx context.Context, w io.Writer, site *site.Site, year string, posts site.Posts) error {
-	heading := fmt.Sprintf("Posts from %s", year)
-	return Layout(heading, site, pages.PostList(heading, posts)).Render(ctx, w)
+// RenderArchivePage renders a page of the posts from a specific year.
+func (r *TemplateRenderer) RenderArchivePage(ctx context.Context, w io.Writer, site *site.Site, year string, page site.Page) error {
+	heading := pageTitle(fmt.Sprintf("Posts from %s", year), page)
+	return PagedLayout(heading, site, page, pages.PostList(heading, page)).Render(ctx, w)
 }
 
 // RenderAbout renders the about page using templ components.
@@ -70,6 +72,21 @@ func (r *TemplateRenderer) RenderNote(ctx context.Context, w io.Writer, s *site.
 	return Layout(note.Frontmatter.Title, s, pages.Note(*note)).Render(ctx, w)
 }
 
+// RenderNoteGraph renders the note graph as JSON.
+func (r *TemplateRenderer) RenderNoteGraph(ctx context.Context, w io.Writer, s *site.Site, graph *site.NoteGraph) error {
+	return graph.Render(w)
+}
+
+// RenderNoteGraphPage renders the page visualizing the note graph.
+func (r *TemplateRenderer) RenderNoteGraphPage(ctx context.Context, w io.Writer, s *site.Site) error {
+	return Layout("Note Graph", s, pages.NoteGraph()).Render(ctx, w)
+}
+
+// RenderNoteHistory renders the revision history page for a note.
+func (r *TemplateRenderer) RenderNoteHistory(ctx context.Context, w io.Writer, s *site.Site, note *site.Note) error {
+	return Layout("History of "+note.Frontmatter.Title, s, pages.NoteHistory(*note)).Render(ctx, w)
+}
+
 // RenderNoteTagIndex renders the note tags index page listing all tags with note counts.
 func (r *TemplateRenderer) RenderNoteTagIndex(ctx context.Context, w io.Writer, s *site.Site) error {
 	notesByTag := s.Notes.IndexByTag()
@@ -97,7 +114,31 @@ func (r *TemplateRenderer) RenderRSSFeed(ctx context.Context, w io.Writer, site
 	return feed.Render(w)
 }
 
+// RenderSearchIndex renders the search index as JSON.
+func (r *TemplateRenderer) RenderSearchIndex(ctx context.Context, w io.Writer, site *site.Site, index *site.SearchIndex) error {
+	return index.Render(w)
+}
+
+// RenderSearchPage renders the search page.
+func (r *TemplateRenderer) RenderSearchPage(ctx context.Context, w io.Writer, site *site.Site) error {
+	return Layout("Search", site, pages.Search()).Render(ctx, w)
+}
+
+// RenderSitemap renders the sitemap as XML.
+func (r *TemplateRenderer) RenderSitemap(ctx context.Context, w io.Writer, site *site.Site, sitemap *site.Sitemap) error {
+	return sitemap.Render(w)
+}
+
 // RenderSeriesIndex renders the index page for a series.
 func (r *TemplateRenderer) RenderSeriesIndex(ctx context.Context, w io.Writer, site *site.Site, series *site.Series) error {
 	return Layout(series.Metadata.Name, site, pages.SeriesIndex(*series)).Render(ctx, w)
 }
+
+// pageTitle adds the page number to title for every page of a paginated list
+// after the first.
+func pageTitle(title string, page site.Page) string {
+	if page.Number <= 1 {
+		return title
+	}
+	return fmt.Sprintf("%s (page %d)", title, page.Number)
+}
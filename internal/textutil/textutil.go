@@ -4,6 +4,8 @@ package textutil
 import (
 	"strings"
 	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // Truncate truncates the input string to the specified maximum length.
@@ -33,3 +35,29 @@ func Truncate(s string, maxLen int) string {
 
 	return strings.TrimSpace(truncated) + "..."
 }
+
+// Slugify converts s into a URL-safe slug. Letters are lowercased and
+// stripped of diacritics, and runs of any other characters are replaced with
+// a single hyphen. Leading and trailing hyphens are removed.
+func Slugify(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining marks left over from decomposing accented letters.
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			pendingHyphen = true
+		}
+	}
+
+	return b.String()
+}
@@ -83,3 +83,54 @@ func TestTruncate(t *testing.T) {
 		})
 	}
 }
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "simple title",
+			input:    "Hello World",
+			expected: "hello-world",
+		},
+		{
+			name:     "punctuation",
+			input:    "Go: The Good Parts!",
+			expected: "go-the-good-parts",
+		},
+		{
+			name:     "repeated separators",
+			input:    "  one -- two__three  ",
+			expected: "one-two-three",
+		},
+		{
+			name:     "digits",
+			input:    "Top 10 Tips for 2024",
+			expected: "top-10-tips-for-2024",
+		},
+		{
+			name:     "diacritics",
+			input:    "Café Crème",
+			expected: "cafe-creme",
+		},
+		{
+			name:     "non-latin characters",
+			input:    "Go 日本語",
+			expected: "go",
+		},
+		{
+			name:     "empty string",
+			input:    "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := textutil.Slugify(tt.input)
+			assert.Equal(t, "slug", tt.expected, result)
+		})
+	}
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/haleyrc/stele/internal/check"
 	"github.com/haleyrc/stele/internal/compiler"
+	"github.com/haleyrc/stele/internal/drafts"
+	"github.com/haleyrc/stele/internal/scaffold"
 	"github.com/haleyrc/stele/internal/server"
 	"github.com/haleyrc/stele/internal/site"
 	"github.com/haleyrc/stele/internal/template"
@@ -36,11 +45,21 @@ func main() {
 	switch command {
 	case "build":
 		runBuild(ctx)
+	case "check":
+		runCheck(ctx)
 	case "dev":
 		runDev(ctx)
+	case "init":
+		runInit()
+	case "new":
+		runNew()
 	case "help":
 		printUsage()
 		os.Exit(0)
+	case "publish":
+		runPublish()
+	case "unpublish":
+		runUnpublish()
 	case "version":
 		os.Exit(0)
 	default:
@@ -63,13 +82,32 @@ func runBuild(ctx context.Context) {
 	buildFlags := flag.NewFlagSet("build", flag.ExitOnError)
 	outDir := buildFlags.String("out", "dist", "Output directory for build")
 	notesExperiment := buildFlags.Bool("notes-experiment", false, "Enable experimental notes feature")
+	checkLinks := buildFlags.String("check-links", "error", "How to handle broken internal links (off, warn, or error)")
+	nowFlag := buildFlags.String("now", "", "Treat this date as the current time when deciding which posts are scheduled")
+	gitDates := buildFlags.Bool("git-dates", false, "Derive note and post modification dates from git history")
 	if err := buildFlags.Parse(os.Args[2:]); err != nil {
 		exitWithError(err)
 	}
 
+	now, nowDateOnly, err := parseNow(*nowFlag)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	linkCheck, err := compiler.ParseLinkCheckMode(*checkLinks)
+	if err != nil {
+		exitWithError(err)
+	}
+
 	site, err := site.New(".", site.SiteOptions{
-		IncludeDrafts:   false,
-		NotesExperiment: *notesExperiment,
+		IncludeDrafts:    false,
+		IncludeScheduled: false,
+		NotesExperiment:  *notesExperiment,
+		Now:              now,
+		NowDateOnly:      nowDateOnly,
+		GitDates:         *gitDates,
+		ImageCache:       filepath.Join(".stele", "images"),
+		DiagramCache:     filepath.Join(".stele", "diagrams"),
 	})
 	if err != nil {
 		exitWithError(err)
@@ -77,26 +115,355 @@ func runBuild(ctx context.Context) {
 
 	renderer := template.NewTemplateRenderer()
 	compiler := compiler.NewCompiler(renderer, site)
+	compiler.LinkCheck = linkCheck
 	if err := compiler.Compile(ctx, *outDir, "."); err != nil {
 		exitWithError(err)
 	}
 }
 
+func runCheck(ctx context.Context) {
+	checkFlags := flag.NewFlagSet("check", flag.ExitOnError)
+	format := checkFlags.String("format", "text", "Output format (text or json)")
+	external := checkFlags.Bool("external", false, "Also check external links")
+	cacheTTL := checkFlags.Duration("cache-ttl", 24*time.Hour, "How long to cache successful external link checks")
+	notesExperiment := checkFlags.Bool("notes-experiment", false, "Enable experimental notes feature")
+	if err := checkFlags.Parse(os.Args[2:]); err != nil {
+		exitWithError(err)
+	}
+
+	if *format != "text" && *format != "json" {
+		exitWithError(fmt.Errorf("unknown format: %s", *format))
+	}
+
+	site, err := site.New(".", site.SiteOptions{
+		IncludeDrafts:    true,
+		IncludeScheduled: true,
+		NotesExperiment:  *notesExperiment,
+		// Diagrams that fail to render are left in the content as errors
+		// so that they're reported along with everything else.
+		DiagramCache:      filepath.Join(".stele", "diagrams"),
+		ShowDiagramErrors: true,
+		// Unresolved wiki links are reported as issues instead.
+		WarnUnresolvedLinks: true,
+	})
+	if err != nil {
+		exitWithError(err)
+	}
+
+	report := check.Run(site)
+
+	if *external {
+		cache, err := check.LoadLinkCache(filepath.Join(".stele", "links.json"), *cacheTTL)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		report.Merge(check.RunExternal(ctx, site, check.NewLinkChecker(cache)))
+
+		if err := cache.Save(); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	if *format == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if report.HasErrors() {
+		os.Exit(1)
+	}
+}
+
+func runInit() {
+	initFlags := flag.NewFlagSet("init", flag.ExitOnError)
+	title := initFlags.String("title", "", "The title of the site")
+	author := initFlags.String("author", "", "The author of the site")
+	description := initFlags.String("description", "", "A description of the site")
+	baseURL := initFlags.String("base-url", "", "The URL where the site will be hosted")
+	about := initFlags.Bool("about", false, "Create an about page")
+	args, err := parseInterspersed(initFlags, os.Args[2:])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	dir := "."
+	switch len(args) {
+	case 0:
+	case 1:
+		dir = args[0]
+	default:
+		exitWithError(fmt.Errorf("usage: stele init [OPTIONS] [DIR]"))
+	}
+
+	// Anything not provided as a flag is prompted for, so passing every flag
+	// allows init to run non-interactively.
+	aboutSet := false
+	initFlags.Visit(func(f *flag.Flag) {
+		if f.Name == "about" {
+			aboutSet = true
+		}
+	})
+
+	in := bufio.NewReader(os.Stdin)
+	for _, field := range []struct {
+		value *string
+		label string
+	}{
+		{title, "Title"},
+		{author, "Author"},
+		{description, "Description"},
+		{baseURL, "Base URL (e.g. https://example.com)"},
+	} {
+		if *field.value == "" {
+			if *field.value, err = prompt(in, field.label); err != nil {
+				exitWithError(err)
+			}
+		}
+	}
+	if !aboutSet {
+		answer, err := prompt(in, "Create an about page? [y/N]")
+		if err != nil {
+			exitWithError(err)
+		}
+		*about = strings.HasPrefix(strings.ToLower(answer), "y")
+	}
+
+	paths, err := scaffold.NewSite(dir, scaffold.SiteOptions{
+		Config: site.SiteConfig{
+			Author:      *author,
+			BaseURL:     *baseURL,
+			Description: *description,
+			Title:       *title,
+		},
+		About: *about,
+	})
+	if err != nil {
+		exitWithError(err)
+	}
+
+	for _, path := range paths {
+		log.Printf("Created %s", path)
+	}
+}
+
+// prompt prints label and returns the trimmed line read from in.
+func prompt(in *bufio.Reader, label string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	line, err := in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("prompt: %s: %w", label, err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func runNew() {
+	newFlags := flag.NewFlagSet("new", flag.ExitOnError)
+	series := newFlags.String("series", "", "Create the post in an existing series")
+	edit := newFlags.Bool("edit", false, "Open the new file in $EDITOR")
+	args, err := parseInterspersed(newFlags, os.Args[2:])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	var path string
+	switch kind := strings.ToLower(args[0]); kind {
+	case "post":
+		if len(args) != 2 {
+			exitWithError(fmt.Errorf(`usage: stele new post [--series SLUG] [--edit] "Title"`))
+		}
+		path, err = scaffold.NewPost(".", args[1], *series)
+	case "series":
+		if len(args) != 3 {
+			exitWithError(fmt.Errorf(`usage: stele new series [--edit] SLUG "Name"`))
+		}
+		path, err = scaffold.NewSeries(".", args[1], args[2])
+	case "note":
+		if len(args) != 2 {
+			exitWithError(fmt.Errorf(`usage: stele new note [--edit] "Title"`))
+		}
+		path, err = scaffold.NewNote(".", args[1])
+	default:
+		exitWithError(fmt.Errorf("unknown content type: %s", kind))
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+
+	log.Printf("Created %s", path)
+
+	if *edit {
+		if err := openEditor(path); err != nil {
+			exitWithError(err)
+		}
+	}
+}
+
+// parseInterspersed parses flags that may appear before, between, or after
+// positional arguments and returns the positional arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// openEditor opens path in the editor named by the EDITOR environment
+// variable and waits for it to exit.
+func openEditor(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		return fmt.Errorf("open editor: EDITOR is not set")
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], path)...) // #nosec G204 - Running the user's own editor is intentional
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("open editor: %w", err)
+	}
+
+	return nil
+}
+
+func runPublish() {
+	publishFlags := flag.NewFlagSet("publish", flag.ExitOnError)
+	dateFlag := publishFlags.String("date", "", "Publish date as YYYY-MM-DD or RFC 3339 (default: today)")
+	args, err := parseInterspersed(publishFlags, os.Args[2:])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if len(args) != 1 {
+		exitWithError(fmt.Errorf("usage: stele publish [--date DATE] PATH|SLUG"))
+	}
+
+	var date time.Time
+	if *dateFlag != "" {
+		if date, err = parseDate(*dateFlag); err != nil {
+			exitWithError(err)
+		}
+	} else {
+		// Today is the date in the site's timezone, which may differ from
+		// the machine's.
+		config, err := site.LoadSiteConfig(".")
+		if err != nil {
+			exitWithError(err)
+		}
+		date = drafts.Today(time.Now(), config.Location())
+	}
+
+	path, err := drafts.Resolve(".", args[0])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if err := drafts.Publish(path, date); err != nil {
+		exitWithError(err)
+	}
+
+	log.Printf("Published %s", path)
+}
+
+// parseDate parses s as either a date (YYYY-MM-DD) or an RFC 3339 timestamp.
+// Dates are interpreted as midnight UTC.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(drafts.DateLayout, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", s)
+}
+
+// parseNow parses the value of a --now flag and reports whether it was given
+// without a time. An empty value returns the zero time, which the site treats
+// as the current time.
+func parseNow(s string) (time.Time, bool, error) {
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.Parse(drafts.DateLayout, s); err == nil {
+		return t, true, nil
+	}
+	t, err := parseDate(s)
+	return t, false, err
+}
+
+func runUnpublish() {
+	unpublishFlags := flag.NewFlagSet("unpublish", flag.ExitOnError)
+	args, err := parseInterspersed(unpublishFlags, os.Args[2:])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if len(args) != 1 {
+		exitWithError(fmt.Errorf("usage: stele unpublish PATH|SLUG"))
+	}
+
+	path, err := drafts.Resolve(".", args[0])
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if err := drafts.Unpublish(path); err != nil {
+		exitWithError(err)
+	}
+
+	log.Printf("Unpublished %s", path)
+}
+
 func runDev(ctx context.Context) {
 	devFlags := flag.NewFlagSet("dev", flag.ExitOnError)
 	port := devFlags.String("port", "3000", "Port to listen on")
-	live := devFlags.Bool("live", false, "Exclude draft posts (live mode)")
+	live := devFlags.Bool("live", false, "Exclude draft and scheduled posts (live mode)")
 	notesExperiment := devFlags.Bool("notes-experiment", false, "Enable experimental notes feature")
+	nowFlag := devFlags.String("now", "", "Treat this date as the current time when deciding which posts are scheduled")
+	gitDates := devFlags.Bool("git-dates", false, "Derive note and post modification dates from git history")
 	if err := devFlags.Parse(os.Args[2:]); err != nil {
 		exitWithError(err)
 	}
 
+	now, nowDateOnly, err := parseNow(*nowFlag)
+	if err != nil {
+		exitWithError(err)
+	}
+
 	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
 	defer cancel()
 
 	cache, err := server.NewSiteCache(".", site.SiteOptions{
-		IncludeDrafts:   !*live,
-		NotesExperiment: *notesExperiment,
+		IncludeDrafts:     !*live,
+		IncludeScheduled:  !*live,
+		NotesExperiment:   *notesExperiment,
+		Now:               now,
+		NowDateOnly:       nowDateOnly,
+		GitDates:          *gitDates,
+		ImageCache:        filepath.Join(".stele", "images"),
+		DiagramCache:      filepath.Join(".stele", "diagrams"),
+		ShowDiagramErrors: true,
+		// Links to notes that haven't been written yet shouldn't stop the
+		// site from reloading while editing.
+		WarnUnresolvedLinks: true,
 	})
 	if err != nil {
 		exitWithError(err)
@@ -147,16 +514,55 @@ USAGE
 
 COMMANDS
   build      Compile static assets for deployment
+  check      Report problems with site content without building
   dev        Run a development server for previewing content
+  init       Create a new site in the current or given directory
+  new        Create a new post, series, or note
+  publish    Publish a draft post
+  unpublish  Turn a published post back into a draft
   help       Print this help message
   version    Print version information
 
 BUILD OPTIONS
   --out               Output directory (default: "dist")
+  --check-links       Broken internal links: "off", "warn", or "error" (default: "error")
+  --now               Date to treat as the current time for scheduled posts (default: now)
+  --git-dates         Derive note and post modification dates from git (default: false)
+  --notes-experiment  Enable experimental notes feature (default: false)
+
+CHECK OPTIONS
+  --format            Output format, "text" or "json" (default: "text")
+  --external          Also check external links (default: false)
+  --cache-ttl         How long to cache successful external link checks (default: 24h)
   --notes-experiment  Enable experimental notes feature (default: false)
 
+INIT OPTIONS
+  --title             The title of the site
+  --author            The author of the site
+  --description       A description of the site
+  --base-url          The URL where the site will be hosted
+  --about             Create an about page (default: false)
+
+NEW
+  stele new post [--series SLUG] "Title"
+  stele new series SLUG "Name"
+  stele new note "Title"
+
+NEW OPTIONS
+  --series            Create the post in an existing series (default: "")
+  --edit              Open the new file in $EDITOR (default: false)
+
+PUBLISH
+  stele publish [--date DATE] PATH|SLUG
+  stele unpublish PATH|SLUG
+
+PUBLISH OPTIONS
+  --date              Publish date as YYYY-MM-DD or RFC 3339 (default: today)
+
 DEV OPTIONS
   --port              Port to listen on (default: "3000")
-  --live              Exclude draft posts (default: false)
+  --live              Exclude draft and scheduled posts (default: false)
+  --now               Date to treat as the current time for scheduled posts (default: now)
+  --git-dates         Derive note and post modification dates from git (default: false)
   --notes-experiment  Enable experimental notes feature (default: false)
 `